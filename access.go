@@ -0,0 +1,162 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/mitchellh/pointerstructure"
+)
+
+// ErrSelectorNotAllowed is returned when an expression references a selector
+// that is forbidden by WithAllowedSelectors or WithDeniedSelectors.
+var ErrSelectorNotAllowed = errors.New("selector is not allowed")
+
+type patternMatch int
+
+const (
+	patternNoMatch patternMatch = iota
+	patternMaybeMatch
+	patternMatches
+)
+
+// selectorPolicy enforces the patterns given to WithAllowedSelectors and
+// WithDeniedSelectors
+type selectorPolicy struct {
+	allowed [][]string
+	denied  [][]string
+}
+
+func newSelectorPolicy(allowed, denied []string) (*selectorPolicy, error) {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil, nil
+	}
+
+	parse := func(patterns []string) ([][]string, error) {
+		var parsed [][]string
+		for _, pattern := range patterns {
+			ptr, err := pointerstructure.Parse(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector pattern %q: %w", pattern, err)
+			}
+			parsed = append(parsed, ptr.Parts)
+		}
+		return parsed, nil
+	}
+
+	var p selectorPolicy
+	var err error
+	if p.allowed, err = parse(allowed); err != nil {
+		return nil, err
+	}
+	if p.denied, err = parse(denied); err != nil {
+		return nil, err
+	}
+	for _, pattern := range p.denied {
+		for i, segment := range pattern {
+			pattern[i] = plainIndex(segment)
+		}
+	}
+	return &p, nil
+}
+
+// plainIndex returns the plain form of the segments pointerstructure would
+// read as a slice index, like "00", "+0" or "0x0" for "0". Since they may
+// also be map keys only the denied patterns, and the paths they are compared
+// with, are converted: a denied index cannot be reached with another form,
+// while an allowed one must be written as it was allowed.
+func plainIndex(segment string) string {
+	if segment == "" {
+		// Decoded as 0 by pointerstructure
+		return "0"
+	}
+	if c := segment[0]; (c < '0' || c > '9') && c != '+' && c != '-' {
+		return segment
+	}
+	i, err := strconv.ParseInt(segment, 0, 64)
+	if err != nil {
+		return segment
+	}
+	return strconv.FormatInt(i, 10)
+}
+
+// plainIndexes returns path with its segments converted by plainIndex,
+// path itself being returned when they already are in plain form
+func plainIndexes(path []string) []string {
+	for i, segment := range path {
+		if plain := plainIndex(segment); plain != segment {
+			converted := make([]string, len(path))
+			copy(converted, path[:i])
+			for j := i; j < len(path); j++ {
+				converted[j] = plainIndex(path[j])
+			}
+			return converted
+		}
+	}
+	return path
+}
+
+// matchPattern reports whether the pattern matches path or one of its
+// parents. A "*" segment in the pattern matches any single segment. When
+// wildcards is set, SelectorWildcard segments in path stand for unknown
+// segments and can only maybe match a literal segment of the pattern.
+func matchPattern(pattern, path []string, wildcards bool) patternMatch {
+	if len(pattern) > len(path) {
+		return patternNoMatch
+	}
+	result := patternMatches
+	for i, segment := range pattern {
+		switch {
+		case segment == "*" || segment == path[i]:
+		case wildcards && path[i] == SelectorWildcard:
+			result = patternMaybeMatch
+		default:
+			return patternNoMatch
+		}
+	}
+	return result
+}
+
+// check verifies that path may be referenced. Paths with wildcards are only
+// rejected when no key or index could make them allowed, the concrete paths
+// are checked again during evaluation.
+func (p *selectorPolicy) check(path []string, wildcards bool) error {
+	plain := plainIndexes(path)
+	for _, pattern := range p.denied {
+		if matchPattern(pattern, plain, wildcards) == patternMatches {
+			return p.error(path)
+		}
+	}
+
+	if len(p.allowed) == 0 {
+		return nil
+	}
+	for _, pattern := range p.allowed {
+		if matchPattern(pattern, path, wildcards) != patternNoMatch {
+			return nil
+		}
+	}
+	return p.error(path)
+}
+
+// checkElements verifies that the elements of the collection at path may be
+// read, as in and not in do. Since the elements are read without their path
+// being referenced, path is rejected when a denied pattern may match one of
+// its descendants.
+func (p *selectorPolicy) checkElements(path []string, wildcards bool) error {
+	plain := plainIndexes(path)
+	for _, pattern := range p.denied {
+		if len(pattern) > len(plain) && matchPattern(pattern[:len(plain)], plain, wildcards) != patternNoMatch {
+			return p.error(path)
+		}
+	}
+	return nil
+}
+
+func (p *selectorPolicy) error(path []string) error {
+	ptr := pointerstructure.Pointer{Parts: path}
+	return fmt.Errorf("%w: %s", ErrSelectorNotAllowed, ptr.String())
+}
@@ -0,0 +1,202 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectorPolicy(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name   string
+		Secret string
+	}
+	type tenant struct {
+		Name   string
+		Labels map[string]string
+		Secret string
+		Items  []item
+		Tokens []string
+	}
+	datum := tenant{
+		Name:   "acme",
+		Labels: map[string]string{"env": "prod", "owner": "alice"},
+		Secret: "hunter2",
+		Items:  []item{{Name: "a", Secret: "s3cr3t"}},
+		Tokens: []string{"hunter2", "pub"},
+	}
+
+	type testCase struct {
+		expression string
+		allowed    []string
+		denied     []string
+		createErr  string
+		evalErr    string
+		result     bool
+	}
+
+	tests := map[string]testCase{
+		"no policy": {
+			expression: `Secret == "hunter2"`,
+			result:     true,
+		},
+		"denied": {
+			expression: `Name == "acme" and Secret == "hunter2"`,
+			denied:     []string{"/Secret"},
+			createErr:  "selector is not allowed: /Secret",
+		},
+		"denied child": {
+			expression: `Labels.owner == "alice"`,
+			denied:     []string{"/Labels/owner"},
+			createErr:  "selector is not allowed: /Labels/owner",
+		},
+		"denied wildcard": {
+			expression: `Items.0.Secret == "s3cr3t"`,
+			denied:     []string{"/Items/*/Secret"},
+			createErr:  "selector is not allowed: /Items/0/Secret",
+		},
+		"in on the parent of a denied index": {
+			expression: `"hunter2" in Tokens`,
+			denied:     []string{"/Tokens/0"},
+			createErr:  "selector is not allowed: /Tokens",
+		},
+		"not in on the parent of a denied index in another form": {
+			expression: `"hunter2" not in Tokens`,
+			denied:     []string{"/Tokens/00"},
+			createErr:  "selector is not allowed: /Tokens",
+		},
+		"in on the parent of a denied element": {
+			expression: `any Items as i { "s" in i.Secret }`,
+			denied:     []string{"/Items/0/Secret/*"},
+			createErr:  "selector is not allowed: /Items/*/Secret",
+		},
+		"in on a collection without denied elements": {
+			expression: `"hunter2" in Tokens`,
+			denied:     []string{"/Items/0"},
+			result:     true,
+		},
+		"emptiness of the parent of a denied index": {
+			expression: `Tokens is not empty`,
+			denied:     []string{"/Tokens/0"},
+			result:     true,
+		},
+		"denied index with leading zeros": {
+			expression: `Items.00.Secret == "s3cr3t"`,
+			denied:     []string{"/Items/0/Secret"},
+			createErr:  "selector is not allowed: /Items/00/Secret",
+		},
+		"denied index with sign": {
+			expression: `Items["+0"].Secret == "s3cr3t"`,
+			denied:     []string{"/Items/0/Secret"},
+			createErr:  "selector is not allowed: /Items/+0/Secret",
+		},
+		"denied hexadecimal index": {
+			expression: `Items["0x0"].Secret == "s3cr3t"`,
+			denied:     []string{"/Items/0/Secret"},
+			createErr:  "selector is not allowed: /Items/0x0/Secret",
+		},
+		"denied index in another form during evaluation": {
+			expression: `any Items as i { i.Secret == "s3cr3t" }`,
+			denied:     []string{"/Items/00/Secret"},
+			evalErr:    "selector is not allowed: /Items/0/Secret",
+		},
+		"denied index pattern in another form": {
+			expression: `Items.0.Secret == "s3cr3t"`,
+			denied:     []string{"/Items/00/Secret"},
+			createErr:  "selector is not allowed: /Items/0/Secret",
+		},
+		"allowed index in another form": {
+			expression: `Items.00.Name == "a"`,
+			allowed:    []string{"/Items/0/Name"},
+			createErr:  "selector is not allowed: /Items/00/Name",
+		},
+		"not denied": {
+			expression: `Labels.env == "prod"`,
+			denied:     []string{"/Labels/owner", "/Secret"},
+			result:     true,
+		},
+		"allowed": {
+			expression: `Name == "acme" and Labels.env == "prod"`,
+			allowed:    []string{"/Name", "/Labels/*"},
+			result:     true,
+		},
+		"not allowed": {
+			expression: `Name == "acme" and Secret == "hunter2"`,
+			allowed:    []string{"/Name", "/Labels/*"},
+			createErr:  "selector is not allowed: /Secret",
+		},
+		"not allowed parent": {
+			expression: `Labels is not empty`,
+			allowed:    []string{"/Labels/env"},
+			createErr:  "selector is not allowed: /Labels",
+		},
+		"deny takes precedence": {
+			expression: `Labels.owner == "alice"`,
+			allowed:    []string{"/Labels"},
+			denied:     []string{"/Labels/owner"},
+			createErr:  "selector is not allowed: /Labels/owner",
+		},
+		"collection denied statically": {
+			expression: `any Items as i { i.Secret == "s3cr3t" }`,
+			denied:     []string{"/Items/*/Secret"},
+			createErr:  "selector is not allowed: /Items/*/Secret",
+		},
+		"collection denied during evaluation": {
+			expression: `any Labels as _, v { v == "alice" }`,
+			denied:     []string{"/Labels/owner"},
+			evalErr:    "selector is not allowed: /Labels/owner",
+		},
+		"collection allowed during evaluation": {
+			expression: `all Labels as _, v { v != "bob" }`,
+			allowed:    []string{"/Labels/env", "/Labels/owner"},
+			result:     true,
+		},
+		"collection not allowed during evaluation": {
+			expression: `all Labels as _, v { v != "bob" }`,
+			allowed:    []string{"/Labels/env"},
+			evalErr:    "selector is not allowed: /Labels/owner",
+		},
+		"collection keys": {
+			expression: `any Labels as k { k == "owner" }`,
+			denied:     []string{"/Labels/owner"},
+			result:     true,
+		},
+		"invalid pattern": {
+			expression: `Name == "acme"`,
+			denied:     []string{"Secret"},
+			createErr:  `invalid selector pattern "Secret"`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			eval, err := CreateEvaluator(tcase.expression,
+				WithAllowedSelectors(tcase.allowed...),
+				WithDeniedSelectors(tcase.denied...))
+			if tcase.createErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tcase.createErr)
+				return
+			}
+			require.NoError(t, err)
+
+			result, err := eval.Evaluate(datum)
+			if tcase.evalErr != "" {
+				require.EqualError(t, err, tcase.evalErr)
+				require.True(t, errors.Is(err, ErrSelectorNotAllowed))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tcase.result, result)
+		})
+	}
+}
@@ -11,6 +11,9 @@ package bexpr
 //go:generate goimports -w grammar/grammar.go
 
 import (
+	"context"
+	"errors"
+
 	"github.com/hashicorp/go-bexpr/grammar"
 	"github.com/mitchellh/pointerstructure"
 )
@@ -21,6 +24,9 @@ import (
 // helpful, for example, when working with protocol buffers' well-known types.
 type ValueTransformationHookFn = pointerstructure.ValueTransformationHookFn
 
+// Evaluator matches an expression against data. It is not modified once
+// created, so it is safe for concurrent use by multiple goroutines as long as
+// the hook function it was created with is.
 type Evaluator struct {
 	// The syntax tree
 	ast                     grammar.Expression
@@ -28,13 +34,27 @@ type Evaluator struct {
 	valueTransformationHook ValueTransformationHookFn
 	unknownVal              *interface{}
 	expression              string
+	selectorPolicy          *selectorPolicy
+
+	// The syntax tree compiled to closures and the options they run with
+	root     *compiledNode
+	evalOpts options
+	// budgeted is true when evaluations have a budget to track
+	budgeted bool
+	// createOpts are the options the evaluator was created with
+	createOpts options
 }
 
 // CreateEvaluator is used to create and configure a new Evaluator, the expression
 // will be used by the evaluator when evaluating against any supplied datum.
 // By default the evaluator will error after 2 million expressions.
+// The regular expressions of matches operators are compiled here, so invalid
+// ones are reported by CreateEvaluator rather than when evaluating.
 // The following Option types are supported:
-// WithHookFn, WithMaxExpressions, WithTagName, WithUnknownValue.
+// WithHookFn, WithMaxExpressions, WithTagName, WithUnknownValue,
+// WithLocalVariable, WithRootOptions, WithAllowedSelectors,
+// WithDeniedSelectors, WithMaxNodeVisits, WithMaxIterations,
+// WithMaxRegexInputSize.
 func CreateEvaluator(expression string, opts ...Option) (*Evaluator, error) {
 	parsedOpts := getOpts(opts...)
 	var parserOpts []grammar.Option
@@ -50,33 +70,233 @@ func CreateEvaluator(expression string, opts ...Option) (*Evaluator, error) {
 		return nil, err
 	}
 
+	return newEvaluator(ast.(grammar.Expression), expression, parsedOpts)
+}
+
+// CreateEvaluatorFromAST creates an Evaluator for an expression that was not
+// parsed from the bexpr syntax, like the ones built by the kubeselector and
+// jsonlogic packages or transformed by grammar.Simplify. It supports the same
+// options as CreateEvaluator, except for WithMaxExpressions which only
+// applies to parsing. Expression returns an empty string for such
+// evaluators. The nodes of ast that are missing, like the operand of a not
+// expression or the value of an equality, and the unknown operators are
+// reported as errors. The evaluator keeps a reference to ast, which must not
+// be modified afterwards.
+func CreateEvaluatorFromAST(ast grammar.Expression, opts ...Option) (*Evaluator, error) {
+	if ast == nil {
+		return nil, errors.New("missing expression")
+	}
+	if err := validate(ast); err != nil {
+		return nil, err
+	}
+	return newEvaluator(ast, "", getOpts(opts...))
+}
+
+func newEvaluator(ast grammar.Expression, expression string, parsedOpts options) (*Evaluator, error) {
+	eval, err := configureEvaluator(ast, expression, parsedOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	c := compiler{budgeted: eval.budgeted}
+	eval.root, err = c.compile(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	return eval, nil
+}
+
+// configureEvaluator creates an Evaluator with everything but its compiled
+// syntax tree
+func configureEvaluator(ast grammar.Expression, expression string, parsedOpts options) (*Evaluator, error) {
 	eval := &Evaluator{
-		ast:                     ast.(grammar.Expression),
+		ast:                     ast,
 		tagName:                 parsedOpts.withTagName,
 		valueTransformationHook: parsedOpts.withHookFn,
 		unknownVal:              parsedOpts.withUnknown,
 		expression:              expression,
+		createOpts:              parsedOpts,
+	}
+
+	var err error
+	eval.selectorPolicy, err = newSelectorPolicy(parsedOpts.withAllowed, parsedOpts.withDenied)
+	if err != nil {
+		return nil, err
+	}
+	if eval.selectorPolicy != nil {
+		for _, usage := range eval.Selectors() {
+			if key := parsedOpts.withKeyVariable; key != "" && usage.LocalVariable == "" && len(usage.Path) == 1 && usage.Path[0] == key {
+				// The key variable of filters is not part of the datum
+				continue
+			}
+			path, wildcards := usage.Path, usage.LocalVariable != ""
+			if usage.CollectionOperator != "" {
+				// Iterating over a collection only exposes its keys, its
+				// elements are checked as they get referenced
+				path, wildcards = append(path, SelectorWildcard), true
+			}
+			if err := eval.selectorPolicy.check(path, wildcards); err != nil {
+				return nil, err
+			}
+			if usage.CollectionOperator == "" && (usage.MatchOperator == grammar.MatchIn || usage.MatchOperator == grammar.MatchNotIn) {
+				// in and not in read every element of lists
+				if err := eval.selectorPolicy.checkElements(path, wildcards); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 
+	opts := []Option{
+		WithTagName(eval.tagName),
+		WithHookFn(eval.valueTransformationHook),
+		WithMaxNodeVisits(parsedOpts.withMaxNodeVisits),
+		WithMaxIterations(parsedOpts.withMaxIterations),
+		WithMaxRegexInputSize(parsedOpts.withMaxRegexInputSize),
+	}
+	if eval.unknownVal != nil {
+		opts = append(opts, WithUnknownValue(*eval.unknownVal))
+	}
+	if eval.selectorPolicy != nil {
+		opts = append(opts, withSelectorPolicy(eval.selectorPolicy))
+	}
+	eval.evalOpts = getOpts(opts...)
+	eval.evalOpts.withLocalVariables = parsedOpts.withLocalVariables
+	eval.evalOpts.withRoots = parsedOpts.withRoots
+	eval.budgeted = newBudget(&eval.evalOpts) != nil
+
 	return eval, nil
 }
 
+// With returns an evaluator for the same expression with opts applied after
+// the options eval was created with, as if they had been given to
+// CreateEvaluator after them. The expression is not parsed again and the
+// regular expressions it uses are not compiled again, so With can be used to
+// evaluate a cached evaluator with request scoped hooks and local variables.
+// WithMaxExpressions has no effect since it only applies to parsing.
+func (eval *Evaluator) With(opts ...Option) (*Evaluator, error) {
+	return eval.derive(eval.withOptions(opts))
+}
+
+// withOptions returns the options eval was created with, with opts applied
+// after them
+func (eval *Evaluator) withOptions(opts []Option) options {
+	parsedOpts := eval.createOpts
+	// The options append to these slices, which are shared with eval
+	parsedOpts.withLocalVariables = parsedOpts.withLocalVariables[:len(parsedOpts.withLocalVariables):len(parsedOpts.withLocalVariables)]
+	parsedOpts.withAllowed = parsedOpts.withAllowed[:len(parsedOpts.withAllowed):len(parsedOpts.withAllowed)]
+	parsedOpts.withDenied = parsedOpts.withDenied[:len(parsedOpts.withDenied):len(parsedOpts.withDenied)]
+	parsedOpts.withRoots = parsedOpts.withRoots[:len(parsedOpts.withRoots):len(parsedOpts.withRoots)]
+	for _, o := range opts {
+		if o != nil {
+			o(&parsedOpts)
+		}
+	}
+	return parsedOpts
+}
+
+// derive returns an evaluator for the same expression created with
+// parsedOpts, reusing the compiled tree of eval when it can
+func (eval *Evaluator) derive(parsedOpts options) (*Evaluator, error) {
+	derived, err := configureEvaluator(eval.ast, eval.expression, parsedOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The compiled tree only depends on whether the evaluations are budgeted
+	if derived.budgeted == eval.budgeted {
+		derived.root = eval.root
+		return derived, nil
+	}
+	c := compiler{budgeted: derived.budgeted}
+	if derived.root, err = c.compile(derived.ast); err != nil {
+		return nil, err
+	}
+	return derived, nil
+}
+
 // Evaluate attempts to match the configured expression against the supplied datum.
 // It returns a value indicating if a match was found and any error that occurred.
 // If an error is returned, the value indicating a match will be false.
 func (eval *Evaluator) Evaluate(datum interface{}) (bool, error) {
-	opts := []Option{
-		WithTagName(eval.tagName),
-		WithHookFn(eval.valueTransformationHook),
+	return eval.root.program(datum, eval.runOptions(nil))
+}
+
+// EvaluateContext is like Evaluate but stops once ctx is done, returning an
+// error wrapping ctx.Err(). The context is checked before evaluating and
+// periodically while iterating over the collections of any and all
+// expressions.
+func (eval *Evaluator) EvaluateContext(ctx context.Context, datum interface{}) (bool, error) {
+	in := newInterrupt(ctx)
+	if err := in.err(); err != nil {
+		return false, err
 	}
-	if eval.unknownVal != nil {
-		opts = append(opts, WithUnknownValue(*eval.unknownVal))
+	return eval.root.program(datum, eval.runOptions(in))
+}
+
+// EvaluateWithOptions is like Evaluate but with opts applied after the
+// options eval was created with, see With. The options only apply to this
+// evaluation, so the selectors are only checked again when opts add local
+// variables or allowed or denied selectors.
+func (eval *Evaluator) EvaluateWithOptions(datum interface{}, opts ...Option) (bool, error) {
+	parsedOpts := eval.withOptions(opts)
+	if len(parsedOpts.withLocalVariables) != len(eval.createOpts.withLocalVariables) ||
+		len(parsedOpts.withAllowed) != len(eval.createOpts.withAllowed) ||
+		len(parsedOpts.withDenied) != len(eval.createOpts.withDenied) ||
+		parsedOpts.withKeyVariable != eval.createOpts.withKeyVariable ||
+		(newBudget(&parsedOpts) != nil) != eval.budgeted {
+		// The selectors have to be checked against the new variables and
+		// patterns, or the tree compiled again to count its node visits
+		derived, err := eval.derive(parsedOpts)
+		if err != nil {
+			return false, err
+		}
+		return derived.Evaluate(datum)
 	}
 
-	return evaluate(eval.ast, datum, opts...)
+	// The other options only change how the compiled tree runs
+	runOpts := eval.evalOpts
+	runOpts.withTagName = parsedOpts.withTagName
+	runOpts.withHookFn = parsedOpts.withHookFn
+	runOpts.withUnknown = parsedOpts.withUnknown
+	runOpts.withRoots = parsedOpts.withRoots
+	runOpts.withMaxNodeVisits = parsedOpts.withMaxNodeVisits
+	runOpts.withMaxIterations = parsedOpts.withMaxIterations
+	runOpts.withMaxRegexInputSize = parsedOpts.withMaxRegexInputSize
+	runOpts.withBudget = newBudget(&runOpts)
+	return eval.root.program(datum, &runOpts)
+}
+
+// EvaluateRoots evaluates the expression against several named data roots,
+// the first segment of each selector picking the root it references, like in
+// `request.User.Team == "core" and env.Region == "eu"`. The keys missing from
+// a root are handled like the keys missing from nested maps, while selectors
+// that do not start with the name of a root are reported as errors. The tag
+// name and hook function used for each root can be set with WithRootOptions.
+func (eval *Evaluator) EvaluateRoots(roots map[string]interface{}) (bool, error) {
+	if roots == nil {
+		roots = map[string]interface{}{}
+	}
+	opts := *eval.runOptions(nil)
+	opts.withRootValues = roots
+	return eval.root.program(roots, &opts)
+}
+
+// runOptions returns the options of an evaluation, which are copied when it
+// has a context or a budget to track
+func (eval *Evaluator) runOptions(in *interrupt) *options {
+	if in == nil && !eval.budgeted {
+		return &eval.evalOpts
+	}
+	opts := eval.evalOpts
+	opts.withInterrupt = in
+	opts.withBudget = newBudget(&opts)
+	return &opts
 }
 
-// Expression can be used to return the initial expression used to create the Evaluator.
+// Expression can be used to return the initial expression used to create the
+// Evaluator. It is empty for evaluators created with CreateEvaluatorFromAST.
 func (eval *Evaluator) Expression() string {
 	return eval.expression
 }
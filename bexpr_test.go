@@ -4,8 +4,14 @@
 package bexpr
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/go-bexpr/grammar"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,6 +31,14 @@ func TestCreateEvaluator(t *testing.T) {
 			expression: "((((((((foo == 1))))))))",
 			err:        "max number of expressions parsed",
 		},
+		"invalid regular expression": {
+			expression: `foo == 3 or Name matches "[a-"`,
+			err:        "failed to compile regular expression \"[a-\": error parsing regexp: missing closing ]: `[a-`",
+		},
+		"invalid regular expression in collection": {
+			expression: `any Items as item { item not matches "(" }`,
+			err:        "failed to compile regular expression \"(\"",
+		},
 	}
 
 	for name, tcase := range tests {
@@ -41,3 +55,399 @@ func TestCreateEvaluator(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateEvaluatorFromAST(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := grammar.Parse("", []byte(`Env == dev or Env == test`))
+	require.NoError(t, err)
+	ast := grammar.Simplify(parsed.(grammar.Expression))
+
+	eval, err := CreateEvaluatorFromAST(ast)
+	require.NoError(t, err)
+	require.Empty(t, eval.Expression())
+
+	match, err := eval.Evaluate(map[string]string{"Env": "test"})
+	require.NoError(t, err)
+	require.True(t, match)
+
+	_, err = CreateEvaluatorFromAST(ast, WithDeniedSelectors("/Env"))
+	require.ErrorIs(t, err, ErrSelectorNotAllowed)
+
+	_, err = CreateEvaluatorFromAST(nil)
+	require.EqualError(t, err, "missing expression")
+}
+
+func TestCreateEvaluatorFromAST_Invalid(t *testing.T) {
+	t.Parallel()
+
+	selector := grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: []string{"Env"}}
+	valid := &grammar.MatchExpression{Selector: selector, Operator: grammar.MatchIsEmpty}
+
+	tests := map[string]struct {
+		ast grammar.Expression
+		err string
+	}{
+		"nil value": {
+			ast: &grammar.MatchExpression{Selector: selector, Operator: grammar.MatchEqual},
+			err: "invalid AST node: missing value of equal expression",
+		},
+		"nil regular expression": {
+			ast: &grammar.MatchExpression{Selector: selector, Operator: grammar.MatchNotMatches},
+			err: "invalid AST node: missing value of not matches expression",
+		},
+		"nil value of is one of": {
+			ast: &grammar.MatchExpression{Selector: selector, Operator: grammar.MatchIsOneOf, Values: []*grammar.MatchValue{{Raw: "dev"}, nil}},
+			err: "invalid AST node: missing value of is one of expression",
+		},
+		"unknown match operator": {
+			ast: &grammar.MatchExpression{Selector: selector, Operator: grammar.MatchOperator(-1)},
+			err: "invalid match operation: -1",
+		},
+		"nil operand": {
+			ast: &grammar.UnaryExpression{Operator: grammar.UnaryOpNot},
+			err: "invalid AST node: missing operand of not expression",
+		},
+		"unknown unary operator": {
+			ast: &grammar.UnaryExpression{Operator: grammar.UnaryOperator(1), Operand: valid},
+			err: "invalid unary operator: 1",
+		},
+		"nil left operand": {
+			ast: &grammar.BinaryExpression{Operator: grammar.BinaryOpAnd, Right: valid},
+			err: "invalid AST node: missing left operand of and expression",
+		},
+		"nil right operand": {
+			ast: &grammar.BinaryExpression{Operator: grammar.BinaryOpOr, Left: valid, Right: (*grammar.MatchExpression)(nil)},
+			err: "invalid AST node: missing right operand of or expression",
+		},
+		"unknown binary operator": {
+			ast: &grammar.BinaryExpression{Operator: grammar.BinaryOperator(2), Left: valid, Right: valid},
+			err: "invalid binary operator: 2",
+		},
+		"nil body": {
+			ast: &grammar.CollectionExpression{Selector: selector, Op: grammar.CollectionOpAny},
+			err: "invalid AST node: missing body of any expression",
+		},
+		"unknown collection operator": {
+			ast: &grammar.CollectionExpression{Selector: selector, Op: "NONE", Inner: valid},
+			err: `invalid collection operator: "NONE"`,
+		},
+		"nested": {
+			ast: &grammar.UnaryExpression{Operator: grammar.UnaryOpNot, Operand: &grammar.BinaryExpression{
+				Operator: grammar.BinaryOpAnd,
+				Left:     valid,
+				Right:    &grammar.MatchExpression{Selector: selector, Operator: grammar.MatchIn},
+			}},
+			err: "invalid AST node: missing value of in expression",
+		},
+		"nil root": {
+			ast: (*grammar.ConstantExpression)(nil),
+			err: "invalid AST node: missing expression",
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := CreateEvaluatorFromAST(tcase.ast, WithDeniedSelectors("/Secret"))
+			require.EqualError(t, err, tcase.err)
+		})
+	}
+}
+
+func TestEvaluator_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	// Run with -race to check that evaluating does not modify the evaluator
+	eval, err := CreateEvaluator(`Name matches "^web-[0-9]+$" and (any Tags as tag { tag matches "^env:" })`)
+	require.NoError(t, err)
+	ast := eval.ast
+
+	type datum struct {
+		Name string
+		Tags []string
+	}
+
+	// The results are checked once the goroutines are done since require
+	// must be called from the goroutine running the test
+	var matches [16][100]bool
+	var errs [16][100]error
+	var wg sync.WaitGroup
+	for i := range matches {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := range matches[i] {
+				value := datum{Name: fmt.Sprintf("web-%d", j), Tags: []string{"env:prod"}}
+				if (i+j)%2 == 0 {
+					value.Tags = []string{"team:core"}
+				}
+				matches[i][j], errs[i][j] = eval.Evaluate(value)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range matches {
+		for j := range matches[i] {
+			require.NoError(t, errs[i][j])
+			require.Equal(t, (i+j)%2 != 0, matches[i][j])
+		}
+	}
+
+	require.Same(t, ast, eval.ast)
+	match := ast.(*grammar.BinaryExpression).Left.(*grammar.MatchExpression)
+	require.Nil(t, match.Value.Converted)
+}
+
+func TestEvaluateContext(t *testing.T) {
+	t.Parallel()
+
+	datum := map[string][]int{"Items": make([]int, 10000)}
+	eval, err := CreateEvaluator(`all Items as item { item == 0 }`)
+	require.NoError(t, err)
+
+	match, err := eval.EvaluateContext(context.Background(), datum)
+	require.NoError(t, err)
+	require.True(t, match)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = eval.EvaluateContext(ctx, datum)
+	require.ErrorIs(t, err, context.Canceled)
+	require.EqualError(t, err, "evaluation interrupted: context canceled")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, err = eval.EvaluateContext(ctx, datum)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Cancel during the evaluation, which should stop iterating soon after
+	ctx, cancel = context.WithCancel(context.Background())
+	calls := 0
+	eval, err = CreateEvaluator(`all Items as item { item == 0 }`, WithHookFn(func(v reflect.Value) reflect.Value {
+		calls++
+		cancel()
+		return v
+	}))
+	require.NoError(t, err)
+	_, err = eval.EvaluateContext(ctx, datum)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, calls, 5000)
+}
+
+func TestEvaluator_With(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Name string `json:"name"`
+	}
+
+	eval, err := CreateEvaluator(`name == "web" and user == "alice"`, WithLocalVariable("user", nil, "alice"))
+	require.NoError(t, err)
+
+	// The local variables of CreateEvaluator are used
+	match, err := eval.Evaluate(map[string]string{"name": "web"})
+	require.NoError(t, err)
+	require.True(t, match)
+
+	derived, err := eval.With(WithTagName("json"))
+	require.NoError(t, err)
+	require.Equal(t, eval.Expression(), derived.Expression())
+	require.Same(t, eval.ast, derived.ast)
+	match, err = derived.Evaluate(record{Name: "web"})
+	require.NoError(t, err)
+	require.True(t, match)
+
+	// Later local variables shadow the earlier ones
+	derived, err = derived.With(WithLocalVariable("user", nil, "bob"))
+	require.NoError(t, err)
+	match, err = derived.Evaluate(record{Name: "web"})
+	require.NoError(t, err)
+	require.False(t, match)
+
+	// eval is left unchanged
+	match, err = eval.Evaluate(map[string]string{"name": "web"})
+	require.NoError(t, err)
+	require.True(t, match)
+
+	// The errors of CreateEvaluator are reported by With
+	_, err = eval.With(WithDeniedSelectors("/name"))
+	require.EqualError(t, err, `selector is not allowed: /name`)
+
+	// Budgets added by With count the node visits
+	derived, err = eval.With(WithMaxNodeVisits(2))
+	require.NoError(t, err)
+	_, err = derived.Evaluate(map[string]string{"name": "web"})
+	require.EqualError(t, err, "evaluation exceeded the node visits limit of 2 (3 node visits, 0 iterations)")
+}
+
+func TestEvaluateWithOptions(t *testing.T) {
+	t.Parallel()
+
+	eval, err := CreateEvaluator(`Name == "web"`)
+	require.NoError(t, err)
+
+	datum := map[string]interface{}{"Name": 42}
+	hook := func(v reflect.Value) reflect.Value {
+		if v.Kind() == reflect.Interface && v.Elem().Kind() == reflect.Int {
+			return reflect.ValueOf("web")
+		}
+		return v
+	}
+	match, err := eval.EvaluateWithOptions(datum, WithHookFn(hook))
+	require.NoError(t, err)
+	require.True(t, match)
+
+	match, err = eval.EvaluateWithOptions(map[string]interface{}{}, WithUnknownValue("web"))
+	require.NoError(t, err)
+	require.True(t, match)
+
+	_, err = eval.EvaluateWithOptions(datum, WithAllowedSelectors("/Port"))
+	require.EqualError(t, err, `selector is not allowed: /Name`)
+
+	match, err = eval.EvaluateWithOptions(datum, WithLocalVariable("Name", nil, "web"))
+	require.NoError(t, err)
+	require.True(t, match)
+
+	_, err = eval.EvaluateWithOptions(datum, WithMaxNodeVisits(0), WithMaxIterations(1))
+	require.EqualError(t, err, `error getting match value in expression: strconv.ParseInt: parsing "web": invalid syntax`)
+
+	match, err = eval.Evaluate(map[string]interface{}{"Name": "db"})
+	require.NoError(t, err)
+	require.False(t, match)
+}
+
+func TestEvaluateWithOptions_Budget(t *testing.T) {
+	t.Parallel()
+
+	eval, err := CreateEvaluator(`Name == "web" or Name == "db"`)
+	require.NoError(t, err)
+	datum := map[string]string{"Name": "db"}
+
+	// A budget added for a single evaluation counts the node visits
+	_, err = eval.EvaluateWithOptions(datum, WithMaxNodeVisits(2))
+	require.EqualError(t, err, "evaluation exceeded the node visits limit of 2 (3 node visits, 0 iterations)")
+
+	budgeted, err := CreateEvaluator(`Name == "web" or Name == "db"`, WithMaxNodeVisits(2))
+	require.NoError(t, err)
+	match, err := budgeted.EvaluateWithOptions(datum, WithMaxNodeVisits(3))
+	require.NoError(t, err)
+	require.True(t, match)
+	_, err = budgeted.EvaluateWithOptions(datum, WithMaxNodeVisits(0))
+	require.NoError(t, err)
+	_, err = budgeted.Evaluate(datum)
+	require.Error(t, err)
+}
+
+func TestEvaluateWithOptions_Allocations(t *testing.T) {
+	eval, err := CreateEvaluator(`Name == "web"`)
+	require.NoError(t, err)
+	datum := map[string]string{"Name": "web"}
+	opts := []Option{WithHookFn(func(v reflect.Value) reflect.Value { return v }), WithTagName("json")}
+
+	evaluate := testing.AllocsPerRun(100, func() {
+		if match, err := eval.Evaluate(datum); err != nil || !match {
+			t.Fatal("unexpected result", match, err)
+		}
+	})
+	withOptions := testing.AllocsPerRun(100, func() {
+		if match, err := eval.EvaluateWithOptions(datum, opts...); err != nil || !match {
+			t.Fatal("unexpected result", match, err)
+		}
+	})
+	// Only the options of the evaluation are allocated, the evaluator is
+	// not configured again
+	require.LessOrEqual(t, withOptions, evaluate+2)
+}
+
+func TestEvaluateRoots(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Team  string `json:"team"`
+		Roles []string
+	}
+	type request struct {
+		User user
+	}
+	type resource struct {
+		Owner user `json:"owner"`
+	}
+
+	roots := map[string]interface{}{
+		"request":  request{User: user{Team: "core", Roles: []string{"admin"}}},
+		"resource": resource{Owner: user{Team: "core"}},
+		"env":      map[string]string{"Region": "eu"},
+	}
+
+	type testCase struct {
+		expression string
+		result     bool
+		err        string
+	}
+
+	tests := map[string]testCase{
+		"roots": {
+			expression: `request.User.Team == "core" and resource.owner.team == "core" and env.Region == "eu"`,
+			result:     true,
+		},
+		"collection": {
+			expression: `any request.User.Roles as role { role == "admin" and env.Region == "eu" }`,
+			result:     true,
+		},
+		"missing key": {
+			expression: `env.Zone != "a"`,
+			result:     true,
+		},
+		"root tag name": {
+			expression: `request.user.team == "core"`,
+			err:        `error finding value in root "request": /user/team at part 0: couldn't find key: struct field with name "user"`,
+		},
+		"unknown root": {
+			expression: `response.Code == 200`,
+			err:        `error finding value in datum: /response/Code does not reference a root`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			eval, err := CreateEvaluator(tcase.expression, WithRootOptions("resource", WithTagName("json")))
+			require.NoError(t, err)
+
+			match, err := eval.EvaluateRoots(roots)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tcase.result, match)
+		})
+	}
+}
+
+func TestEvaluateRoots_HookFn(t *testing.T) {
+	t.Parallel()
+
+	// Only the values of the env root are transformed
+	eval, err := CreateEvaluator(`request.Region == "eu" and env.Region == "EU"`,
+		WithRootOptions("env", WithHookFn(func(v reflect.Value) reflect.Value {
+			if v.Kind() == reflect.String {
+				return reflect.ValueOf(strings.ToUpper(v.String()))
+			}
+			return v
+		})))
+	require.NoError(t, err)
+
+	match, err := eval.EvaluateRoots(map[string]interface{}{
+		"request": map[string]string{"Region": "eu"},
+		"env":     map[string]string{"Region": "eu"},
+	})
+	require.NoError(t, err)
+	require.True(t, match)
+}
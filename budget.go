@@ -0,0 +1,132 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Limit identifies an evaluation budget
+type Limit int
+
+const (
+	// LimitNodeVisits bounds the number of nodes of the expression visited
+	// by an evaluation, see WithMaxNodeVisits
+	LimitNodeVisits Limit = iota
+	// LimitIterations bounds the number of iterations of the any and all
+	// expressions of an evaluation, see WithMaxIterations
+	LimitIterations
+	// LimitRegexInputSize bounds the size of the values matched against
+	// regular expressions, see WithMaxRegexInputSize
+	LimitRegexInputSize
+)
+
+func (l Limit) String() string {
+	switch l {
+	case LimitNodeVisits:
+		return "node visits"
+	case LimitIterations:
+		return "iterations"
+	case LimitRegexInputSize:
+		return "regular expression input size"
+	default:
+		return "unknown"
+	}
+}
+
+// LimitError is returned when an evaluation exceeds one of its budgets. It
+// reports the budget consumed when the evaluation stopped.
+type LimitError struct {
+	// Limit is the budget that was exceeded and Max its value
+	Limit Limit
+	Max   uint64
+
+	// NodeVisits and Iterations are the number of nodes visited and
+	// iterations done by the evaluation
+	NodeVisits uint64
+	Iterations uint64
+	// RegexInputSize is the size of the value that exceeded
+	// LimitRegexInputSize
+	RegexInputSize uint64
+}
+
+func (e *LimitError) Error() string {
+	if e.Limit == LimitRegexInputSize {
+		return fmt.Sprintf("evaluation exceeded the %s limit of %d with a value of size %d (%d node visits, %d iterations)",
+			e.Limit, e.Max, e.RegexInputSize, e.NodeVisits, e.Iterations)
+	}
+	return fmt.Sprintf("evaluation exceeded the %s limit of %d (%d node visits, %d iterations)",
+		e.Limit, e.Max, e.NodeVisits, e.Iterations)
+}
+
+// budget tracks the resources consumed by an evaluation, a zero maximum
+// meaning no limit. It is shared by every node of an evaluation.
+type budget struct {
+	maxNodeVisits     uint64
+	maxIterations     uint64
+	maxRegexInputSize uint64
+
+	nodeVisits uint64
+	iterations uint64
+}
+
+// newBudget returns nil when opts sets no budget, which disables the checks
+func newBudget(opts *options) *budget {
+	if opts.withMaxNodeVisits == 0 && opts.withMaxIterations == 0 && opts.withMaxRegexInputSize == 0 {
+		return nil
+	}
+	return &budget{
+		maxNodeVisits:     opts.withMaxNodeVisits,
+		maxIterations:     opts.withMaxIterations,
+		maxRegexInputSize: opts.withMaxRegexInputSize,
+	}
+}
+
+func (b *budget) exceeded(limit Limit, max uint64) *LimitError {
+	return &LimitError{
+		Limit:      limit,
+		Max:        max,
+		NodeVisits: b.nodeVisits,
+		Iterations: b.iterations,
+	}
+}
+
+// visit is called for each node of the expression that is evaluated
+func (b *budget) visit() error {
+	if b == nil {
+		return nil
+	}
+	b.nodeVisits++
+	if b.maxNodeVisits != 0 && b.nodeVisits > b.maxNodeVisits {
+		return b.exceeded(LimitNodeVisits, b.maxNodeVisits)
+	}
+	return nil
+}
+
+// iterate is called for each iteration of the any and all expressions
+func (b *budget) iterate() error {
+	if b == nil {
+		return nil
+	}
+	b.iterations++
+	if b.maxIterations != 0 && b.iterations > b.maxIterations {
+		return b.exceeded(LimitIterations, b.maxIterations)
+	}
+	return nil
+}
+
+// regexInput is called with the values about to be matched against a regular
+// expression, the ones that cannot be are left for the matcher to reject
+func (b *budget) regexInput(value reflect.Value) error {
+	if b == nil || b.maxRegexInputSize == 0 || !value.IsValid() || !value.Type().ConvertibleTo(byteSliceTyp) {
+		return nil
+	}
+	if size := uint64(value.Len()); size > b.maxRegexInputSize {
+		err := b.exceeded(LimitRegexInputSize, b.maxRegexInputSize)
+		err.RegexInputSize = size
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		expression string
+		opts       []Option
+		datum      interface{}
+		result     bool
+		err        *LimitError
+	}
+
+	numbers := make([]int, 100)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	datum := map[string]interface{}{
+		"A":     numbers,
+		"B":     numbers,
+		"Name":  strings.Repeat("a", 100),
+		"Bytes": []byte("abc"),
+	}
+
+	tests := map[string]testCase{
+		"node visits": {
+			expression: `A is not empty and B is not empty`,
+			opts:       []Option{WithMaxNodeVisits(3)},
+			datum:      datum,
+			result:     true,
+		},
+		"node visits exceeded": {
+			expression: `A is not empty and B is not empty`,
+			opts:       []Option{WithMaxNodeVisits(2)},
+			datum:      datum,
+			err:        &LimitError{Limit: LimitNodeVisits, Max: 2, NodeVisits: 3},
+		},
+		"node visits in collections": {
+			expression: `all A as a { a != -1 }`,
+			opts:       []Option{WithMaxNodeVisits(50)},
+			datum:      datum,
+			err:        &LimitError{Limit: LimitNodeVisits, Max: 50, NodeVisits: 51, Iterations: 50},
+		},
+		"iterations": {
+			expression: `any A as a { a == 99 }`,
+			opts:       []Option{WithMaxIterations(100)},
+			datum:      datum,
+			result:     true,
+		},
+		"nested iterations exceeded": {
+			expression: `any A as a { any B as b { b == 99 and a == 99 } }`,
+			opts:       []Option{WithMaxIterations(500)},
+			datum:      datum,
+			err:        &LimitError{Limit: LimitIterations, Max: 500, NodeVisits: 1000, Iterations: 501},
+		},
+		"regular expression input size": {
+			expression: `Name matches "^a+$"`,
+			opts:       []Option{WithMaxRegexInputSize(100)},
+			datum:      datum,
+			result:     true,
+		},
+		"regular expression input size exceeded": {
+			expression: `Name not matches "^a+$"`,
+			opts:       []Option{WithMaxRegexInputSize(99)},
+			datum:      datum,
+			err:        &LimitError{Limit: LimitRegexInputSize, Max: 99, NodeVisits: 1, RegexInputSize: 100},
+		},
+		"regular expression input size of bytes": {
+			expression: `Bytes matches "b"`,
+			opts:       []Option{WithMaxRegexInputSize(2)},
+			datum:      datum,
+			err:        &LimitError{Limit: LimitRegexInputSize, Max: 2, NodeVisits: 1, RegexInputSize: 3},
+		},
+		"several budgets": {
+			expression: `any A as a { Name matches "^a" }`,
+			opts:       []Option{WithMaxNodeVisits(10), WithMaxIterations(10), WithMaxRegexInputSize(10)},
+			datum:      datum,
+			err:        &LimitError{Limit: LimitRegexInputSize, Max: 10, NodeVisits: 2, Iterations: 1, RegexInputSize: 100},
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			eval, err := CreateEvaluator(tcase.expression, tcase.opts...)
+			require.NoError(t, err)
+
+			// Each evaluation has its own budget
+			for i := 0; i < 2; i++ {
+				match, err := eval.Evaluate(tcase.datum)
+				require.Equal(t, tcase.result, match)
+				if tcase.err == nil {
+					require.NoError(t, err)
+				} else {
+					var limitErr *LimitError
+					require.True(t, errors.As(err, &limitErr))
+					require.Equal(t, tcase.err, limitErr)
+				}
+			}
+
+			// Explanations consume the same budget
+			explanation, err := eval.Explain(tcase.datum)
+			require.Equal(t, tcase.result, explanation.Result)
+			if tcase.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, tcase.err, err)
+			}
+		})
+	}
+}
+
+func TestBudget_Filter(t *testing.T) {
+	t.Parallel()
+
+	// The budget applies to the evaluation of each element
+	ast, err := grammar.Parse("", []byte(`any Tags as tag { tag == "b" }`))
+	require.NoError(t, err)
+	flt, err := CreateFilterFromAST(ast.(grammar.Expression), WithMaxIterations(2))
+	require.NoError(t, err)
+
+	results, err := flt.Execute([]map[string][]string{{"Tags": {"a", "b"}}, {"Tags": {"c"}}})
+	require.NoError(t, err)
+	require.Equal(t, []map[string][]string{{"Tags": {"a", "b"}}}, results)
+
+	_, err = flt.Execute([]map[string][]string{{"Tags": {"a", "c", "b"}}})
+	require.EqualError(t, err, "evaluation exceeded the iterations limit of 2 (3 node visits, 3 iterations)")
+}
+
+func TestLimitError(t *testing.T) {
+	t.Parallel()
+
+	err := &LimitError{Limit: LimitNodeVisits, Max: 10, NodeVisits: 11, Iterations: 4}
+	require.EqualError(t, err, "evaluation exceeded the node visits limit of 10 (11 node visits, 4 iterations)")
+
+	err = &LimitError{Limit: LimitRegexInputSize, Max: 10, NodeVisits: 1, RegexInputSize: 12}
+	require.EqualError(t, err, "evaluation exceeded the regular expression input size limit of 10 with a value of size 12 (1 node visits, 0 iterations)")
+}
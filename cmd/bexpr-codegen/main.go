@@ -0,0 +1,74 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Command bexpr-codegen generates Go functions implementing bexpr expressions
+// for a type of the package in the current directory. It is meant to be run
+// by go generate:
+//
+//	//go:generate go run github.com/hashicorp/go-bexpr/cmd/bexpr-codegen -type Record IsWeb='Name matches "^web"'
+//
+// Each argument is the name of a function to generate followed by the
+// expression it implements, the functions have the signature
+// func(*Record) bool.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/codegen"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "bexpr-codegen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("bexpr-codegen", flag.ContinueOnError)
+	typeName := flags.String("type", "", "name of the type the expressions are evaluated against")
+	output := flags.String("output", "", "output file, <type>_bexpr.go by default")
+	tagName := flags.String("tag", "bexpr", "struct tag used to rename fields")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: bexpr-codegen -type T [-output file] [-tag name] Name=expression...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *typeName == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("a type and at least one function are required")
+	}
+	if *output == "" {
+		*output = strings.ToLower(*typeName) + "_bexpr.go"
+	}
+
+	pkg, err := codegen.Load(".", *output)
+	if err != nil {
+		return err
+	}
+	typ, err := codegen.LookupType(pkg, *typeName)
+	if err != nil {
+		return err
+	}
+
+	var funcs []codegen.Function
+	for _, arg := range flags.Args() {
+		name, expression, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid function %q, expected Name=expression", arg)
+		}
+		funcs = append(funcs, codegen.Function{Name: name, Expression: expression, Type: typ})
+	}
+
+	src, err := codegen.Generate(codegen.Config{Package: pkg, TagName: *tagName}, funcs...)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*output, src, 0o644)
+}
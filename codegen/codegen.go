@@ -0,0 +1,774 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package codegen generates Go functions implementing bexpr expressions for
+// a given type, for the hot paths where the cost of reflection matters more
+// than the flexibility of evaluating expressions at runtime.
+//
+// A generated function has the signature func(*T) bool and returns what
+// Evaluate would for the same value, false being returned whenever Evaluate
+// would fail. Selectors are resolved statically like pointerstructure
+// resolves them, including the NotPresentDisposition of missing map keys and
+// the "any" and "all" quantifiers over slices, arrays and maps.
+//
+// Values whose type is only known at runtime cannot be resolved statically,
+// so selectors going through interfaces and json.Number values are rejected,
+// as are struct fields that can never be found since Evaluate would always
+// fail for them.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// Function describes a function to generate.
+type Function struct {
+	// Name of the generated function
+	Name string
+	// Expression implemented by the function
+	Expression string
+	// AST is used instead of parsing Expression when set, Expression then
+	// only documents the function
+	AST grammar.Expression
+	// Type is T in the func(*T) bool signature of the function
+	Type types.Type
+}
+
+// Config configures Generate.
+type Config struct {
+	// Package is the package the generated file belongs to, the types of
+	// this package are not qualified in the generated code.
+	Package *types.Package
+	// TagName is the struct tag used to rename fields, "bexpr" by default.
+	TagName string
+}
+
+// Generate returns the formatted source of a Go file implementing funcs.
+func Generate(config Config, funcs ...Function) ([]byte, error) {
+	if config.TagName == "" {
+		config.TagName = "bexpr"
+	}
+	g := &generator{config: config, imports: make(map[string]string)}
+
+	var body bytes.Buffer
+	for _, fn := range funcs {
+		code, err := g.function(fn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", fn.Name, err)
+		}
+		body.WriteString("\n")
+		body.WriteString(code)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by bexpr-codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n", config.Package.Name())
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		out.WriteString("\nimport (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+		out.WriteString(")\n")
+	}
+	if len(g.regexps) > 0 {
+		out.WriteString("\nvar (\n")
+		for i, pattern := range g.regexps {
+			fmt.Fprintf(&out, "\tbexprRegexp%d = regexp.MustCompile(%q)\n", i, pattern)
+		}
+		out.WriteString(")\n")
+	}
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// binding is a local variable of a collection expression
+type binding struct {
+	name string
+	// expr is the Go expression of the value of the variable
+	expr string
+	typ  types.Type
+	// key is true for indexes and map keys, which have no JSON Pointer
+	key bool
+	// pathLen is the length of the JSON Pointer the variable replaces
+	pathLen int
+	used    *bool
+}
+
+type generator struct {
+	config  Config
+	imports map[string]string
+	regexps []string
+
+	out     *strings.Builder
+	counter int
+	root    types.Type
+}
+
+func (g *generator) line(format string, args ...interface{}) {
+	fmt.Fprintf(g.out, format+"\n", args...)
+}
+
+func (g *generator) name(prefix string) string {
+	g.counter++
+	return fmt.Sprintf("%s%d", prefix, g.counter)
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.config.Package {
+		return ""
+	}
+	g.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+func (g *generator) function(fn Function) (string, error) {
+	ast := fn.AST
+	if ast == nil {
+		parsed, err := grammar.Parse("", []byte(fn.Expression))
+		if err != nil {
+			return "", err
+		}
+		ast = parsed.(grammar.Expression)
+	}
+
+	var out strings.Builder
+	g.out = &out
+	g.counter = 0
+	g.root = types.NewPointer(fn.Type)
+
+	if expression := strings.TrimSpace(fn.Expression); expression != "" {
+		g.line("// %s reports whether v matches the bexpr expression", fn.Name)
+		g.line("//")
+		for _, line := range strings.Split(expression, "\n") {
+			g.line("//\t%s", strings.TrimSpace(line))
+		}
+	} else {
+		g.line("// %s reports whether v matches its bexpr expression", fn.Name)
+	}
+	g.line("func %s(v %s) bool {", fn.Name, types.TypeString(g.root, g.qualifier))
+	r, ok, err := g.expr(ast, nil)
+	if err != nil {
+		return "", err
+	}
+	g.line("return %s && %s", ok, r)
+	g.line("}")
+	return out.String(), nil
+}
+
+// expr emits the code evaluating node and returns the names of the variables
+// holding its result and whether it was evaluated without error.
+func (g *generator) expr(node grammar.Expression, scope []binding) (string, string, error) {
+	r, ok := g.name("r"), g.name("ok")
+	switch node := node.(type) {
+	case *grammar.ConstantExpression:
+		g.line("%s, %s := %t, true", r, ok, node.Value)
+	case *grammar.UnaryExpression:
+		if node.Operator != grammar.UnaryOpNot {
+			return "", "", fmt.Errorf("invalid unary operator: %s", node.Operator)
+		}
+		operandR, operandOk, err := g.expr(node.Operand, scope)
+		if err != nil {
+			return "", "", err
+		}
+		g.line("%s, %s := !%s, %s", r, ok, operandR, operandOk)
+	case *grammar.BinaryExpression:
+		leftR, leftOk, err := g.expr(node.Left, scope)
+		if err != nil {
+			return "", "", err
+		}
+		g.line("%s, %s := %s, %s", r, ok, leftR, leftOk)
+		switch node.Operator {
+		case grammar.BinaryOpAnd:
+			g.line("if %s && %s {", leftOk, leftR)
+		case grammar.BinaryOpOr:
+			g.line("if %s && !%s {", leftOk, leftR)
+		default:
+			return "", "", fmt.Errorf("invalid binary operator: %s", node.Operator)
+		}
+		rightR, rightOk, err := g.expr(node.Right, scope)
+		if err != nil {
+			return "", "", err
+		}
+		g.line("%s, %s = %s, %s", r, ok, rightR, rightOk)
+		g.line("}")
+	case *grammar.MatchExpression:
+		g.line("%s, %s := func() (bool, bool) {", r, ok)
+		if err := g.match(node, scope); err != nil {
+			return "", "", err
+		}
+		g.line("}()")
+	case *grammar.CollectionExpression:
+		g.line("%s, %s := func() (bool, bool) {", r, ok)
+		if err := g.collection(node, scope); err != nil {
+			return "", "", err
+		}
+		g.line("}()")
+	default:
+		return "", "", fmt.Errorf("invalid AST node: %T", node)
+	}
+	return r, ok, nil
+}
+
+// fail is the statement returned for the evaluations that would fail
+const fail = "return false, false"
+
+// navigate emits the code resolving sel, returning from the enclosing
+// function with missing when its last key is absent from a map whose
+// NotPresentDisposition applies. It returns the Go expression of the value
+// and its type, done is true when the emitted code always returns.
+func (g *generator) navigate(sel grammar.Selector, scope []binding, missing string) (string, types.Type, int, bool, error) {
+	path := sel.Path
+	cur, typ := "v", g.root
+	pathLen := len(path)
+	for i := len(scope) - 1; i >= 0; i-- {
+		b := scope[i]
+		if len(path) == 0 || b.name != path[0] {
+			continue
+		}
+		if b.key {
+			if len(path) > 1 {
+				// Indexes and keys have no fields
+				g.line(fail)
+				return "", nil, 0, true, nil
+			}
+			*b.used = true
+			return b.expr, b.typ, pathLen, false, nil
+		}
+		*b.used = true
+		cur, typ = b.expr, b.typ
+		path = path[1:]
+		pathLen = b.pathLen + len(path)
+		break
+	}
+
+	for i, part := range path {
+		// pointerstructure goes through any number of pointers
+		pointer := false
+		for {
+			ptr, ok := typ.Underlying().(*types.Pointer)
+			if !ok {
+				break
+			}
+			g.line("if %s == nil {", cur)
+			g.line(fail)
+			g.line("}")
+			cur, typ, pointer = "(*"+cur+")", ptr.Elem(), true
+		}
+
+		switch u := typ.Underlying().(type) {
+		case *types.Struct:
+			field, err := g.structField(u, part)
+			if err != nil {
+				return "", nil, 0, false, fmt.Errorf("invalid selector %s: %w", sel, err)
+			}
+			cur, typ = cur+"."+field.Name(), field.Type()
+		case *types.Map:
+			key, ok := g.mapKey(u.Key(), part)
+			if !ok {
+				g.line(fail)
+				return "", nil, 0, true, nil
+			}
+			value, found := g.name("x"), g.name("found")
+			g.line("%s, %s := %s[%s]", value, found, cur, key)
+			g.line("if !%s {", found)
+			// The disposition only applies when the parent found is a map,
+			// not a pointer to one
+			if i == len(path)-1 && pathLen >= 2 && !pointer {
+				g.line("return %s", missing)
+			} else {
+				g.line(fail)
+			}
+			g.line("}")
+			cur, typ = value, u.Elem()
+		case *types.Slice, *types.Array:
+			index, err := strconv.ParseInt(part, 0, 0)
+			if err != nil || index < 0 {
+				g.line(fail)
+				return "", nil, 0, true, nil
+			}
+			if a, ok := u.(*types.Array); ok && index >= a.Len() {
+				g.line(fail)
+				return "", nil, 0, true, nil
+			}
+			g.line("if %d >= len(%s) {", index, cur)
+			g.line(fail)
+			g.line("}")
+			cur = fmt.Sprintf("%s[%d]", cur, index)
+			if s, ok := u.(*types.Slice); ok {
+				typ = s.Elem()
+			} else {
+				typ = u.(*types.Array).Elem()
+			}
+		case *types.Interface:
+			return "", nil, 0, false, fmt.Errorf("selector %s goes through %s whose dynamic type cannot be resolved statically", sel, types.TypeString(typ, g.qualifier))
+		default:
+			// pointerstructure cannot go through other kinds
+			g.line(fail)
+			return "", nil, 0, true, nil
+		}
+	}
+	return cur, typ, pathLen, false, nil
+}
+
+// structField mirrors the struct field lookup of pointerstructure
+func (g *generator) structField(s *types.Struct, part string) (*types.Var, error) {
+	var found *types.Var
+	ignored := false
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		tag := reflect.StructTag(s.Tag(i)).Get(g.config.TagName)
+		if tag == "" {
+			if field.Name() == part {
+				found = field
+			}
+			continue
+		}
+		if idx := strings.Index(tag, ","); idx != -1 {
+			tag = tag[:idx]
+		}
+		if strings.Contains(tag, "|") {
+			return nil, fmt.Errorf("pointer struct tag cannot contain the '|' character")
+		}
+		if tag == "-" {
+			if field.Name() == part {
+				found, ignored = field, true
+			}
+			continue
+		}
+		if tag == part {
+			return field, nil
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("struct field with name %q not found", part)
+	}
+	if ignored {
+		return nil, fmt.Errorf("struct field %q is ignored and cannot be used", part)
+	}
+	return found, nil
+}
+
+// mapKey converts part to a key of the given type, ok is false when
+// pointerstructure would fail to
+func (g *generator) mapKey(key types.Type, part string) (string, bool) {
+	basic, isBasic := key.Underlying().(*types.Basic)
+	if !isBasic {
+		return "", false
+	}
+	var literal string
+	switch info := basic.Info(); {
+	case info&types.IsString != 0:
+		literal = strconv.Quote(part)
+	case info&types.IsInteger != 0 && info&types.IsUnsigned != 0:
+		u, err := strconv.ParseUint(part, 0, 64)
+		if err != nil {
+			return "", false
+		}
+		literal = strconv.FormatUint(u, 10)
+	case info&types.IsInteger != 0:
+		i, err := strconv.ParseInt(part, 0, 64)
+		if err != nil {
+			return "", false
+		}
+		literal = strconv.FormatInt(i, 10)
+	default:
+		return "", false
+	}
+	if types.Identical(key, types.Typ[basic.Kind()]) {
+		return literal, true
+	}
+	return fmt.Sprintf("%s(%s)", types.TypeString(key, g.qualifier), literal), true
+}
+
+// kind classifies types like the reflect.Kind the evaluation switches on
+func kind(typ types.Type) reflect.Kind {
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		switch u.Kind() {
+		case types.Bool:
+			return reflect.Bool
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+			return reflect.Int64
+		case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+			return reflect.Uint64
+		case types.Float32:
+			return reflect.Float32
+		case types.Float64:
+			return reflect.Float64
+		case types.String:
+			return reflect.String
+		}
+		return reflect.Invalid
+	case *types.Pointer:
+		return reflect.Pointer
+	case *types.Map:
+		return reflect.Map
+	case *types.Slice:
+		return reflect.Slice
+	case *types.Array:
+		return reflect.Array
+	case *types.Chan:
+		return reflect.Chan
+	case *types.Struct:
+		return reflect.Struct
+	case *types.Interface:
+		return reflect.Interface
+	default:
+		return reflect.Invalid
+	}
+}
+
+func isJSONNumber(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "encoding/json" && named.Obj().Name() == "Number"
+}
+
+// equality returns the condition comparing expr to raw, ok is false when
+// the comparison fails like it does during evaluation
+func (g *generator) equality(expr string, typ types.Type, raw string) (string, bool) {
+	switch kind(typ) {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("bool(%s) == %t", expr, b), true
+	case reflect.Int64:
+		i, err := strconv.ParseInt(raw, 0, 64)
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("int64(%s) == %d", expr, i), true
+	case reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 0, 64)
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("uint64(%s) == %d", expr, u), true
+	case reflect.Float32, reflect.Float64:
+		bits, conv := 64, "float64"
+		if kind(typ) == reflect.Float32 {
+			bits, conv = 32, "float32"
+		}
+		f, err := strconv.ParseFloat(raw, bits)
+		if err != nil {
+			return "", false
+		}
+		switch {
+		case math.IsNaN(f):
+			return "false", true
+		case math.IsInf(f, 0):
+			g.imports["math"] = "math"
+			return fmt.Sprintf("%s(%s) == %s(math.Inf(%d))", conv, expr, conv, int(math.Copysign(1, f))), true
+		}
+		return fmt.Sprintf("%s(%s) == %s(%s)", conv, expr, conv, strconv.FormatFloat(f, 'g', -1, bits)), true
+	case reflect.String:
+		return fmt.Sprintf("string(%s) == %s", expr, strconv.Quote(raw)), true
+	default:
+		return "", false
+	}
+}
+
+func (g *generator) match(node *grammar.MatchExpression, scope []binding) error {
+	missing := fmt.Sprintf("%t, true", node.Operator.NotPresentDisposition())
+	expr, typ, _, done, err := g.navigate(node.Selector, scope, missing)
+	if err != nil || done {
+		return err
+	}
+
+	negated := false
+	op := node.Operator
+	switch op {
+	case grammar.MatchNotEqual, grammar.MatchNotIn, grammar.MatchIsNotEmpty,
+		grammar.MatchNotMatches, grammar.MatchIsNotNil, grammar.MatchIsNotOneOf:
+		negated = true
+	}
+	// result returns cond as the result of the match
+	result := func(cond string) {
+		if negated {
+			g.line("return !(%s), true", cond)
+		} else {
+			g.line("return %s, true", cond)
+		}
+	}
+
+	if isJSONNumber(typ) {
+		return fmt.Errorf("selector %s references a json.Number whose kind is only known at runtime", node.Selector)
+	}
+	if kind(typ) == reflect.Interface {
+		return fmt.Errorf("selector %s references %s whose dynamic type cannot be resolved statically", node.Selector, types.TypeString(typ, g.qualifier))
+	}
+	// The value found is dereferenced once, a nil pointer being nil and
+	// failing every other operation
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		g.line("if %s == nil {", expr)
+		switch op {
+		case grammar.MatchIsNil, grammar.MatchIsNotNil:
+			result("true")
+		default:
+			g.line(fail)
+		}
+		g.line("}")
+		expr, typ = "(*"+expr+")", ptr.Elem()
+		if isJSONNumber(typ) || kind(typ) == reflect.Interface {
+			return fmt.Errorf("selector %s references %s whose dynamic type cannot be resolved statically", node.Selector, types.TypeString(typ, g.qualifier))
+		}
+	}
+
+	switch op {
+	case grammar.MatchEqual, grammar.MatchNotEqual:
+		cond, ok := g.equality(expr, typ, node.Value.Raw)
+		if !ok {
+			g.line(fail)
+			return nil
+		}
+		result(cond)
+	case grammar.MatchIsOneOf, grammar.MatchIsNotOneOf:
+		var conds []string
+		for _, value := range node.Values {
+			cond, ok := g.equality(expr, typ, value.Raw)
+			if !ok {
+				// The evaluation fails once it reaches this value
+				if len(conds) > 0 {
+					g.line("if %s {", strings.Join(conds, " || "))
+					result("true")
+					g.line("}")
+				}
+				g.line(fail)
+				return nil
+			}
+			conds = append(conds, cond)
+		}
+		result(strings.Join(conds, " || "))
+	case grammar.MatchIn, grammar.MatchNotIn:
+		return g.in(node, expr, typ, result)
+	case grammar.MatchIsEmpty, grammar.MatchIsNotEmpty:
+		switch kind(typ) {
+		case reflect.Map, reflect.Slice, reflect.Array, reflect.Chan, reflect.String:
+			result(fmt.Sprintf("len(%s) == 0", expr))
+		default:
+			g.line(fail)
+		}
+	case grammar.MatchIsNil, grammar.MatchIsNotNil:
+		switch kind(typ) {
+		case reflect.Map, reflect.Slice, reflect.Chan, reflect.Pointer:
+			result(fmt.Sprintf("%s == nil", expr))
+		case reflect.Struct:
+			result("false")
+		default:
+			g.line(fail)
+		}
+	case grammar.MatchMatches, grammar.MatchNotMatches:
+		if !types.ConvertibleTo(typ, types.NewSlice(types.Typ[types.Byte])) {
+			g.line(fail)
+			return nil
+		}
+		if _, err := regexp.Compile(node.Value.Raw); err != nil {
+			return fmt.Errorf("failed to compile regular expression %q: %v", node.Value.Raw, err)
+		}
+		g.imports["regexp"] = "regexp"
+		re := fmt.Sprintf("bexprRegexp%d", len(g.regexps))
+		g.regexps = append(g.regexps, node.Value.Raw)
+		if kind(typ) == reflect.String {
+			result(fmt.Sprintf("%s.MatchString(string(%s))", re, expr))
+		} else {
+			result(fmt.Sprintf("%s.Match([]byte(%s))", re, expr))
+		}
+	default:
+		return fmt.Errorf("invalid match operation: %d", op)
+	}
+	return nil
+}
+
+func (g *generator) in(node *grammar.MatchExpression, expr string, typ types.Type, result func(string)) error {
+	raw := node.Value.Raw
+	switch u := typ.Underlying().(type) {
+	case *types.Map:
+		// The evaluation looks a string up, which only works for string
+		// and empty interface keys
+		key := u.Key()
+		if iface, ok := key.Underlying().(*types.Interface); !types.Identical(key, types.Typ[types.String]) && (!ok || !iface.Empty()) {
+			return fmt.Errorf("selector %s references a map whose keys cannot be compared with strings", node.Selector)
+		}
+		found := g.name("found")
+		g.line("_, %s := %s[%s]", found, expr, strconv.Quote(raw))
+		result(found)
+		return nil
+	case *types.Slice, *types.Array:
+		var elem types.Type
+		if s, ok := u.(*types.Slice); ok {
+			elem = s.Elem()
+		} else {
+			elem = u.(*types.Array).Elem()
+		}
+		if kind(elem) == reflect.Interface {
+			return fmt.Errorf("selector %s references %s whose elements have no static type", node.Selector, types.TypeString(typ, g.qualifier))
+		}
+
+		i := g.name("i")
+		item := fmt.Sprintf("%s[%s]", expr, i)
+		itemType := elem
+		pointer := false
+		if ptr, ok := elem.Underlying().(*types.Pointer); ok {
+			pointer = true
+			itemType = ptr.Elem()
+			if _, ok := itemType.Underlying().(*types.Pointer); ok {
+				return fmt.Errorf("selector %s references %s whose elements are pointers to pointers", node.Selector, types.TypeString(typ, g.qualifier))
+			}
+			if kind(itemType) == reflect.Interface {
+				return fmt.Errorf("selector %s references %s whose elements have no static type", node.Selector, types.TypeString(typ, g.qualifier))
+			}
+		}
+		cond, ok := g.equality("*"+item, itemType, raw)
+		if !pointer {
+			cond, ok = g.equality(item, itemType, raw)
+		}
+		if !ok {
+			g.line(fail)
+			return nil
+		}
+
+		g.line("for %s := range %s {", i, expr)
+		if pointer {
+			g.line("if %s == nil {", item)
+			g.line(fail)
+			g.line("}")
+		}
+		g.line("if %s {", cond)
+		result("true")
+		g.line("}")
+		g.line("}")
+		result("false")
+		return nil
+	}
+
+	if kind(typ) == reflect.String {
+		g.imports["strings"] = "strings"
+		result(fmt.Sprintf("strings.Contains(string(%s), %s)", expr, strconv.Quote(raw)))
+		return nil
+	}
+	g.line(fail)
+	return nil
+}
+
+func (g *generator) collection(node *grammar.CollectionExpression, scope []binding) error {
+	isAll := node.Op == grammar.CollectionOpAll
+	expr, typ, pathLen, done, err := g.navigate(node.Selector, scope, fmt.Sprintf("%t, true", isAll))
+	if err != nil || done {
+		return err
+	}
+
+	nb := node.NameBinding
+	conflict := nb.Mode == grammar.CollectionBindIndexAndValue && nb.Index == nb.Value
+
+	var keyVar string
+	var keyType, elemType types.Type
+	var elemExpr string
+	var bindings []binding
+	keyUsed, elemUsed := false, false
+	switch u := typ.Underlying().(type) {
+	case *types.Map:
+		if !types.Identical(u.Key(), types.Typ[types.String]) {
+			// Only maps indexed with strings can be iterated over
+			g.line(fail)
+			return nil
+		}
+		keyVar, keyType, elemType = g.name("k"), u.Key(), u.Elem()
+		elemExpr = fmt.Sprintf("%s[%s]", expr, keyVar)
+		for _, name := range []string{nb.Default, nb.Index} {
+			if name != "" {
+				bindings = append(bindings, binding{name: name, expr: keyVar, typ: keyType, key: true, used: &keyUsed})
+			}
+		}
+		if nb.Value != "" {
+			bindings = append(bindings, binding{name: nb.Value, expr: elemExpr, typ: elemType, pathLen: pathLen + 1, used: &elemUsed})
+		}
+	case *types.Slice, *types.Array:
+		keyVar, keyType = g.name("i"), types.Typ[types.Int]
+		if s, ok := u.(*types.Slice); ok {
+			elemType = s.Elem()
+		} else {
+			elemType = u.(*types.Array).Elem()
+		}
+		elemExpr = fmt.Sprintf("%s[%s]", expr, keyVar)
+		if nb.Index != "" {
+			bindings = append(bindings, binding{name: nb.Index, expr: keyVar, typ: keyType, key: true, used: &keyUsed})
+		}
+		for _, name := range []string{nb.Default, nb.Value} {
+			if name != "" {
+				bindings = append(bindings, binding{name: name, expr: elemExpr, typ: elemType, pathLen: pathLen + 1, used: &elemUsed})
+			}
+		}
+	case *types.Interface:
+		return fmt.Errorf("selector %s references %s whose dynamic type cannot be resolved statically", node.Selector, types.TypeString(typ, g.qualifier))
+	default:
+		// Not a list or a map
+		g.line(fail)
+		return nil
+	}
+
+	if conflict {
+		g.line("for range %s {", expr)
+		g.line(fail)
+		g.line("}")
+		g.line("return %t, true", isAll)
+		return nil
+	}
+
+	// Generate the body first to know whether the key is used
+	saved := g.out
+	var body strings.Builder
+	g.out = &body
+	r, ok, err := g.expr(node.Inner, append(append([]binding(nil), scope...), bindings...))
+	if err != nil {
+		return err
+	}
+	g.line("if !%s {", ok)
+	g.line(fail)
+	g.line("}")
+	if isAll {
+		g.line("if !%s {", r)
+		g.line("return false, true")
+	} else {
+		g.line("if %s {", r)
+		g.line("return true, true")
+	}
+	g.line("}")
+	g.out = saved
+
+	if keyUsed || elemUsed {
+		g.line("for %s := range %s {", keyVar, expr)
+	} else {
+		g.line("for range %s {", expr)
+	}
+	g.out.WriteString(body.String())
+	g.line("}")
+	g.line("return %t, true", isAll)
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package codegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSource = `package test
+
+import "encoding/json"
+
+type Record struct {
+	Name    string
+	Alias   string ` + "`bexpr:\"nick\"`" + `
+	Hidden  string ` + "`bexpr:\"-\"`" + `
+	Tags    []string
+	Labels  map[string]string
+	Named   map[Key]string
+	Codes   map[int]string
+	Any     interface{}
+	Values  []interface{}
+	Number  json.Number
+	Deep    []**int
+	private string
+}
+
+type Key string
+`
+
+func loadTestType(t *testing.T) (*types.Package, types.Type) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", testSource, 0)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("test", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+	typ, err := LookupType(pkg, "Record")
+	require.NoError(t, err)
+	return pkg, typ
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	pkg, typ := loadTestType(t)
+	src, err := Generate(Config{Package: pkg},
+		Function{Name: "IsWeb", Expression: `Name matches "^web" and "prod" in Labels`, Type: typ},
+		Function{Name: "HasTag", Expression: `any Tags as tag { tag == "a" }`, Type: typ},
+	)
+	require.NoError(t, err)
+
+	code := string(src)
+	require.Contains(t, code, "// Code generated by bexpr-codegen. DO NOT EDIT.\n\npackage test\n")
+	require.Contains(t, code, "\t\"regexp\"\n")
+	require.Contains(t, code, "bexprRegexp0 = regexp.MustCompile(\"^web\")")
+	require.Contains(t, code, "//\tName matches \"^web\" and \"prod\" in Labels\nfunc IsWeb(v *Record) bool {")
+	require.Contains(t, code, "func HasTag(v *Record) bool {")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		expression string
+		err        string
+	}
+
+	tests := map[string]testCase{
+		"Syntax": {
+			expression: `Name ==`,
+			err:        "failed to generate Match: 1:8 (7): no match found",
+		},
+		"Invalid regular expression": {
+			expression: `Name matches "("`,
+			err:        `failed to compile regular expression "("`,
+		},
+		"Unknown field": {
+			expression: `Missing == "a"`,
+			err:        `invalid selector Missing: struct field with name "Missing" not found`,
+		},
+		"Renamed field": {
+			expression: `Alias == "a"`,
+			err:        `invalid selector Alias: struct field with name "Alias" not found`,
+		},
+		"Ignored field": {
+			expression: `Hidden == "a"`,
+			err:        `invalid selector Hidden: struct field "Hidden" is ignored and cannot be used`,
+		},
+		"Unexported field": {
+			expression: `private == "a"`,
+			err:        `invalid selector private: struct field with name "private" not found`,
+		},
+		"Interface": {
+			expression: `Any == "a"`,
+			err:        "selector Any references interface{} whose dynamic type cannot be resolved statically",
+		},
+		"Through interface": {
+			expression: `Any.Name == "a"`,
+			err:        "selector Any.Name goes through interface{} whose dynamic type cannot be resolved statically",
+		},
+		"Interface elements": {
+			expression: `"a" in Values`,
+			err:        "selector Values references []interface{} whose elements have no static type",
+		},
+		"Pointer to pointer elements": {
+			expression: `1 in Deep`,
+			err:        "selector Deep references []**int whose elements are pointers to pointers",
+		},
+		"JSON number": {
+			expression: `Number == 1`,
+			err:        "selector Number references a json.Number whose kind is only known at runtime",
+		},
+		"Named map keys": {
+			expression: `"a" in Named`,
+			err:        "selector Named references a map whose keys cannot be compared with strings",
+		},
+		"Integer map keys": {
+			expression: `"1" in Codes`,
+			err:        "selector Codes references a map whose keys cannot be compared with strings",
+		},
+	}
+
+	pkg, typ := loadTestType(t)
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Generate(Config{Package: pkg}, Function{Name: "Match", Expression: tcase.expression, Type: typ})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tcase.err)
+		})
+	}
+}
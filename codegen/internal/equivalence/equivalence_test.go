@@ -0,0 +1,289 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package equivalence
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"math"
+	"os"
+	"testing"
+
+	bexpr "github.com/hashicorp/go-bexpr"
+	"github.com/hashicorp/go-bexpr/codegen"
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update generated.go")
+
+type testCase struct {
+	name       string
+	expression string
+	ast        grammar.Expression
+}
+
+func oneOf(op grammar.MatchOperator, selector string, values ...string) grammar.Expression {
+	match := &grammar.MatchExpression{
+		Selector: grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: []string{selector}},
+		Operator: op,
+	}
+	for _, value := range values {
+		match.Values = append(match.Values, &grammar.MatchValue{Raw: value})
+	}
+	return match
+}
+
+var testCases = []testCase{
+	{name: "Equal", expression: `Name == "web"`},
+	{name: "NotEqual", expression: `Name != "web"`},
+	{name: "TaggedField", expression: `nick == "w"`},
+	{name: "IntEqual", expression: `Port == 80`},
+	{name: "IntHex", expression: `Port == "0x50"`},
+	{name: "IntInvalid", expression: `Port == "http"`},
+	{name: "FloatEqual", expression: `Weight == 1.5`},
+	{name: "FloatNaN", expression: `Weight != "NaN"`},
+	{name: "FloatInf", expression: `Weight == "-Inf"`},
+	{name: "Float32Equal", expression: `Ratio == 0.1`},
+	{name: "UintEqual", expression: `Count == 3`},
+	{name: "UintNegative", expression: `Count == -3`},
+	{name: "BoolEqual", expression: `Enabled == true`},
+	{name: "BoolInvalid", expression: `Enabled == "yes"`},
+	{name: "NamedInt", expression: `Level == 2`},
+	{name: "BytesMatches", expression: `Raw matches "^ab"`},
+	{name: "Matches", expression: `Name matches "^w.b$"`},
+	{name: "NotMatches", expression: `Name not matches "^w"`},
+	{name: "MatchesInt", expression: `Port matches "8"`},
+	{name: "StringIn", expression: `"e" in Name`},
+	{name: "StringNotIn", expression: `Name not contains "e"`},
+	{name: "SliceIn", expression: `"b" in Tags`},
+	{name: "SliceNotIn", expression: `"b" not in Tags`},
+	{name: "ArrayIn", expression: `Ports contains 443`},
+	{name: "ArrayInInvalid", expression: `Ports contains "https"`},
+	{name: "MapIn", expression: `"env" in Labels`},
+	{name: "MapNotIn", expression: `"env" not in Labels`},
+	{name: "InterfaceMapIn", expression: `"a" in Any`},
+	{name: "IntIn", expression: `"8" in Port`},
+	{name: "IsEmpty", expression: `Tags is empty`},
+	{name: "IsNotEmpty", expression: `Labels is not empty`},
+	{name: "StringIsEmpty", expression: `Name is empty`},
+	{name: "IntIsEmpty", expression: `Port is empty`},
+	{name: "ChanIsEmpty", expression: `Ch is empty`},
+	{name: "IsNil", expression: `Owner is nil`},
+	{name: "IsNotNil", expression: `Owner is not nil`},
+	{name: "SliceIsNil", expression: `Tags is nil`},
+	{name: "PointerPointerIsNil", expression: `Backup is nil`},
+	{name: "StringIsNil", expression: `Name is nil`},
+	{name: "PointerEqual", expression: `Nick == "n"`},
+	{name: "PointerNotEqual", expression: `Nick != "n"`},
+	{name: "PointerField", expression: `Owner.Name == "alice"`},
+	{name: "PointerPointerField", expression: `Backup.Name == "bob"`},
+	{name: "Index", expression: `Tags.1 == "b"`},
+	{name: "IndexHex", expression: `"/Tags/0x1" == "b"`},
+	{name: "IndexOutOfRange", expression: `Tags.5 != "b"`},
+	{name: "ArrayIndex", expression: `Ports.2 == 443`},
+	{name: "ArrayIndexOutOfRange", expression: `Ports.3 == 443`},
+	{name: "InvalidIndex", expression: `Tags.x == "b"`},
+	{name: "PointerElementIndex", expression: `Scores.0 == 7`},
+	{name: "StructInSlice", expression: `Items.0.Name == "first"`},
+	{name: "MapKey", expression: `Labels.env == "prod"`},
+	{name: "MissingKey", expression: `Labels.missing == "prod"`},
+	{name: "MissingKeyNotEqual", expression: `Labels.missing != "prod"`},
+	{name: "MissingKeyIsEmpty", expression: `Labels.missing is empty`},
+	{name: "MissingKeyNotIn", expression: `"a" not in Labels.missing`},
+	{name: "MissingNestedKey", expression: `Meta.a.b == "c"`},
+	{name: "MissingNestedKeyNotEqual", expression: `Meta.missing.b != "c"`},
+	{name: "IntKey", expression: `Codes.200 == "ok"`},
+	{name: "IntKeyInvalid", expression: `Codes.ok == "ok"`},
+	{name: "NamedKey", expression: `NamedKeys.a == "b"`},
+	{name: "MissingKeyBehindPointer", expression: `MetaPtr.missing != "a"`},
+	{name: "KeyBehindPointer", expression: `MetaPtr.a == "b"`},
+	{name: "TopLevelJSONPointer", expression: `"/Owner/Attrs/team" == "core"`},
+	{name: "MissingKeyBehindNilPointer", expression: `Owner.Attrs.team != "core"`},
+	{name: "FieldOfString", expression: `Name.First == "a"`},
+	{name: "And", expression: `Port == 80 and Name == "web"`},
+	{name: "Or", expression: `Port == 80 or Name == "web"`},
+	{name: "Not", expression: `not Enabled == true`},
+	{name: "NotError", expression: `not Port == "http"`},
+	{name: "ErrorShortCircuit", expression: `Port == 1 and Port == "http"`},
+	{name: "ErrorOr", expression: `Port == "http" or Port == 80`},
+	{name: "Any", expression: `any Tags as tag { tag == "b" }`},
+	{name: "All", expression: `all Tags as tag { tag != "b" }`},
+	{name: "AnyIndex", expression: `any Tags as i, tag { i == 1 and tag == "b" }`},
+	{name: "AnyIndexOnly", expression: `any Tags as i, _ { i == 2 }`},
+	{name: "AnyUnused", expression: `any Tags as tag { Port == 80 }`},
+	{name: "AnyArray", expression: `any Ports as p { p == 443 }`},
+	{name: "AnyStruct", expression: `any Items as item { item.Name == "second" and item.id == 2 }`},
+	{name: "AnyPointers", expression: `any Refs as ref { ref.Name == "second" }`},
+	{name: "AllPointers", expression: `all Refs as ref { ref.Name != "third" }`},
+	{name: "AnyMapKey", expression: `any Labels as k { k == "env" }`},
+	{name: "AnyMapValue", expression: `any Labels as _, v { v == "prod" }`},
+	{name: "AllMapKeyValue", expression: `all Labels as k, v { k != "env" or v == "prod" }`},
+	{name: "MapKeyField", expression: `any Labels as k { k.First == "a" }`},
+	{name: "BindingConflict", expression: `all Labels as k, k { Port == 80 }`},
+	{name: "IntKeyCollection", expression: `any Codes as k { k == "ok" }`},
+	{name: "NotCollection", expression: `any Name as c { c == "w" }`},
+	{name: "MissingCollection", expression: `all Meta.missing as v { v == "a" }`},
+	{name: "MissingCollectionAny", expression: `any Meta.missing as v { v == "a" }`},
+	{name: "Nested", expression: `any Items as item { any item.Tags as tag { tag == "y" } }`},
+	{name: "NestedMap", expression: `all Groups as name, items { any items as item { item.Name == name } }`},
+	{name: "NestedMissingKey", expression: `any Items as item { item.Attrs.missing != "a" }`},
+	{name: "NestedShadowing", expression: `any Items as x { any x.Tags as x { x == "y" } }`},
+	{name: "NestedOuterBinding", expression: `any Items as item { any item.Tags as tag { item.Name == "first" and tag == "y" } }`},
+	{name: "ElementMissingKey", expression: `any Meta as _, m { m.b != "c" }`},
+	{name: "ElementPointerField", expression: `any Items as item { item.Owner.Name == "alice" }`},
+	{name: "ElementIsNil", expression: `all Items as item { item.Owner is nil }`},
+	{name: "OneOf", ast: oneOf(grammar.MatchIsOneOf, "Port", "22", "80")},
+	{name: "NotOneOf", ast: oneOf(grammar.MatchIsNotOneOf, "Name", "web", "db")},
+	{name: "OneOfInvalid", ast: oneOf(grammar.MatchIsOneOf, "Port", "80", "http", "22")},
+	{name: "Constant", ast: &grammar.BinaryExpression{
+		Left:     &grammar.ConstantExpression{Value: true},
+		Operator: grammar.BinaryOpOr,
+		Right:    oneOf(grammar.MatchIsOneOf, "Port", "http"),
+	}},
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func records() []Record {
+	owner := &Owner{Name: "bob"}
+	return []Record{
+		{},
+		{
+			Name:      "web",
+			Alias:     "w",
+			Hidden:    "h",
+			Port:      80,
+			Weight:    1.5,
+			Ratio:     0.1,
+			Count:     3,
+			Enabled:   true,
+			Level:     2,
+			Raw:       []byte("abc"),
+			Tags:      []string{"a", "b", "c"},
+			Ports:     [3]int{80, 8080, 443},
+			Scores:    []*int{intPtr(7), nil},
+			Labels:    map[string]string{"env": "prod", "team": "core"},
+			Counters:  map[string]int{"hits": 3},
+			Codes:     map[int]string{200: "ok"},
+			NamedKeys: map[Key]string{"a": "b"},
+			Meta:      map[string]map[string]string{"a": {"b": "c"}, "d": {}},
+			MetaPtr:   &map[string]string{"a": "b"},
+			Any:       map[interface{}]string{"a": "b", 1: "c"},
+			Owner:     &Owner{Name: "alice", Attrs: map[string]string{"team": "core"}},
+			Backup:    &owner,
+			Nick:      stringPtr("n"),
+			Items: []Item{
+				{ID: 1, Name: "first", Tags: []string{"x", "y"}, Owner: &Owner{Name: "alice"}},
+				{ID: 2, Name: "second", Attrs: map[string]string{"missing": "a"}},
+			},
+			Refs: []*Item{{Name: "first"}, {Name: "second"}},
+			Groups: map[string][]Item{
+				"first":  {{Name: "first"}},
+				"second": {{Name: "other"}, {Name: "second"}},
+			},
+			Ch: make(chan int, 1),
+		},
+		{
+			Name:    "db",
+			Port:    5432,
+			Weight:  math.Inf(-1),
+			Ratio:   float32(math.NaN()),
+			Level:   1,
+			Tags:    []string{},
+			Labels:  map[string]string{},
+			Meta:    map[string]map[string]string{"a": {"b": "d"}},
+			Owner:   &Owner{},
+			Backup:  new(*Owner),
+			Nick:    stringPtr("x"),
+			Items:   []Item{{Name: "first", Tags: []string{"y"}}},
+			Refs:    []*Item{nil},
+			Groups:  map[string][]Item{"first": {{Name: "first"}}},
+			MetaPtr: new(map[string]string),
+		},
+	}
+}
+
+func generate(t *testing.T) []byte {
+	t.Helper()
+
+	pkg, err := codegen.Load(".", "generated.go")
+	require.NoError(t, err)
+	typ, err := codegen.LookupType(pkg, "Record")
+	require.NoError(t, err)
+
+	funcs := make([]codegen.Function, 0, len(testCases))
+	for _, tc := range testCases {
+		funcs = append(funcs, codegen.Function{
+			Name:       tc.name,
+			Expression: tc.expression,
+			AST:        tc.ast,
+			Type:       typ,
+		})
+	}
+	src, err := codegen.Generate(codegen.Config{Package: pkg}, funcs...)
+	require.NoError(t, err)
+
+	// Index the functions for TestEquivalence
+	var buf bytes.Buffer
+	buf.Write(src)
+	buf.WriteString("\nvar generated = map[string]func(*Record) bool{\n")
+	for _, tc := range testCases {
+		fmt.Fprintf(&buf, "%q: %s,\n", tc.name, tc.name)
+	}
+	buf.WriteString("}\n")
+	src, err = format.Source(buf.Bytes())
+	require.NoError(t, err)
+	return src
+}
+
+func TestGenerated(t *testing.T) {
+	src := generate(t)
+	if *update {
+		require.NoError(t, os.WriteFile("generated.go", src, 0o644))
+		return
+	}
+	existing, err := os.ReadFile("generated.go")
+	require.NoError(t, err)
+	require.Equal(t, string(existing), string(src), "generated.go is out of date, run the tests with -update")
+}
+
+func TestEquivalence(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fn, ok := generated[tc.name]
+			require.True(t, ok, "generated.go is out of date, run the tests with -update")
+
+			var eval *bexpr.Evaluator
+			var err error
+			if tc.ast != nil {
+				eval, err = bexpr.CreateEvaluatorFromAST(tc.ast)
+			} else {
+				eval, err = bexpr.CreateEvaluator(tc.expression)
+			}
+			require.NoError(t, err)
+
+			for i, record := range records() {
+				expected, err := eval.Evaluate(record)
+				if err != nil {
+					expected = false
+				}
+				require.Equal(t, expected, fn(&record), "record %d, Evaluate returned %v", i, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,1982 @@
+// Code generated by bexpr-codegen. DO NOT EDIT.
+
+package equivalence
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+var (
+	bexprRegexp0 = regexp.MustCompile("^ab")
+	bexprRegexp1 = regexp.MustCompile("^w.b$")
+	bexprRegexp2 = regexp.MustCompile("^w")
+)
+
+// Equal reports whether v matches the bexpr expression
+//
+//	Name == "web"
+func Equal(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return string((*v).Name) == "web", true
+	}()
+	return ok2 && r1
+}
+
+// NotEqual reports whether v matches the bexpr expression
+//
+//	Name != "web"
+func NotEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return !(string((*v).Name) == "web"), true
+	}()
+	return ok2 && r1
+}
+
+// TaggedField reports whether v matches the bexpr expression
+//
+//	nick == "w"
+func TaggedField(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return string((*v).Alias) == "w", true
+	}()
+	return ok2 && r1
+}
+
+// IntEqual reports whether v matches the bexpr expression
+//
+//	Port == 80
+func IntEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return int64((*v).Port) == 80, true
+	}()
+	return ok2 && r1
+}
+
+// IntHex reports whether v matches the bexpr expression
+//
+//	Port == "0x50"
+func IntHex(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return int64((*v).Port) == 80, true
+	}()
+	return ok2 && r1
+}
+
+// IntInvalid reports whether v matches the bexpr expression
+//
+//	Port == "http"
+func IntInvalid(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// FloatEqual reports whether v matches the bexpr expression
+//
+//	Weight == 1.5
+func FloatEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return float64((*v).Weight) == float64(1.5), true
+	}()
+	return ok2 && r1
+}
+
+// FloatNaN reports whether v matches the bexpr expression
+//
+//	Weight != "NaN"
+func FloatNaN(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return !(false), true
+	}()
+	return ok2 && r1
+}
+
+// FloatInf reports whether v matches the bexpr expression
+//
+//	Weight == "-Inf"
+func FloatInf(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return float64((*v).Weight) == float64(math.Inf(-1)), true
+	}()
+	return ok2 && r1
+}
+
+// Float32Equal reports whether v matches the bexpr expression
+//
+//	Ratio == 0.1
+func Float32Equal(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return float32((*v).Ratio) == float32(0.1), true
+	}()
+	return ok2 && r1
+}
+
+// UintEqual reports whether v matches the bexpr expression
+//
+//	Count == 3
+func UintEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return uint64((*v).Count) == 3, true
+	}()
+	return ok2 && r1
+}
+
+// UintNegative reports whether v matches the bexpr expression
+//
+//	Count == -3
+func UintNegative(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// BoolEqual reports whether v matches the bexpr expression
+//
+//	Enabled == true
+func BoolEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return bool((*v).Enabled) == true, true
+	}()
+	return ok2 && r1
+}
+
+// BoolInvalid reports whether v matches the bexpr expression
+//
+//	Enabled == "yes"
+func BoolInvalid(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// NamedInt reports whether v matches the bexpr expression
+//
+//	Level == 2
+func NamedInt(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return int64((*v).Level) == 2, true
+	}()
+	return ok2 && r1
+}
+
+// BytesMatches reports whether v matches the bexpr expression
+//
+//	Raw matches "^ab"
+func BytesMatches(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return bexprRegexp0.Match([]byte((*v).Raw)), true
+	}()
+	return ok2 && r1
+}
+
+// Matches reports whether v matches the bexpr expression
+//
+//	Name matches "^w.b$"
+func Matches(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return bexprRegexp1.MatchString(string((*v).Name)), true
+	}()
+	return ok2 && r1
+}
+
+// NotMatches reports whether v matches the bexpr expression
+//
+//	Name not matches "^w"
+func NotMatches(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return !(bexprRegexp2.MatchString(string((*v).Name))), true
+	}()
+	return ok2 && r1
+}
+
+// MatchesInt reports whether v matches the bexpr expression
+//
+//	Port matches "8"
+func MatchesInt(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// StringIn reports whether v matches the bexpr expression
+//
+//	"e" in Name
+func StringIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return strings.Contains(string((*v).Name), "e"), true
+	}()
+	return ok2 && r1
+}
+
+// StringNotIn reports whether v matches the bexpr expression
+//
+//	Name not contains "e"
+func StringNotIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return !(strings.Contains(string((*v).Name), "e")), true
+	}()
+	return ok2 && r1
+}
+
+// SliceIn reports whether v matches the bexpr expression
+//
+//	"b" in Tags
+func SliceIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Tags {
+			if string((*v).Tags[i3]) == "b" {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// SliceNotIn reports whether v matches the bexpr expression
+//
+//	"b" not in Tags
+func SliceNotIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Tags {
+			if string((*v).Tags[i3]) == "b" {
+				return !(true), true
+			}
+		}
+		return !(false), true
+	}()
+	return ok2 && r1
+}
+
+// ArrayIn reports whether v matches the bexpr expression
+//
+//	Ports contains 443
+func ArrayIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Ports {
+			if int64((*v).Ports[i3]) == 443 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// ArrayInInvalid reports whether v matches the bexpr expression
+//
+//	Ports contains "https"
+func ArrayInInvalid(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// MapIn reports whether v matches the bexpr expression
+//
+//	"env" in Labels
+func MapIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		_, found3 := (*v).Labels["env"]
+		return found3, true
+	}()
+	return ok2 && r1
+}
+
+// MapNotIn reports whether v matches the bexpr expression
+//
+//	"env" not in Labels
+func MapNotIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		_, found3 := (*v).Labels["env"]
+		return !(found3), true
+	}()
+	return ok2 && r1
+}
+
+// InterfaceMapIn reports whether v matches the bexpr expression
+//
+//	"a" in Any
+func InterfaceMapIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		_, found3 := (*v).Any["a"]
+		return found3, true
+	}()
+	return ok2 && r1
+}
+
+// IntIn reports whether v matches the bexpr expression
+//
+//	"8" in Port
+func IntIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// IsEmpty reports whether v matches the bexpr expression
+//
+//	Tags is empty
+func IsEmpty(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return len((*v).Tags) == 0, true
+	}()
+	return ok2 && r1
+}
+
+// IsNotEmpty reports whether v matches the bexpr expression
+//
+//	Labels is not empty
+func IsNotEmpty(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return !(len((*v).Labels) == 0), true
+	}()
+	return ok2 && r1
+}
+
+// StringIsEmpty reports whether v matches the bexpr expression
+//
+//	Name is empty
+func StringIsEmpty(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return len((*v).Name) == 0, true
+	}()
+	return ok2 && r1
+}
+
+// IntIsEmpty reports whether v matches the bexpr expression
+//
+//	Port is empty
+func IntIsEmpty(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// ChanIsEmpty reports whether v matches the bexpr expression
+//
+//	Ch is empty
+func ChanIsEmpty(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return len((*v).Ch) == 0, true
+	}()
+	return ok2 && r1
+}
+
+// IsNil reports whether v matches the bexpr expression
+//
+//	Owner is nil
+func IsNil(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Owner == nil {
+			return true, true
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// IsNotNil reports whether v matches the bexpr expression
+//
+//	Owner is not nil
+func IsNotNil(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Owner == nil {
+			return !(true), true
+		}
+		return !(false), true
+	}()
+	return ok2 && r1
+}
+
+// SliceIsNil reports whether v matches the bexpr expression
+//
+//	Tags is nil
+func SliceIsNil(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return (*v).Tags == nil, true
+	}()
+	return ok2 && r1
+}
+
+// PointerPointerIsNil reports whether v matches the bexpr expression
+//
+//	Backup is nil
+func PointerPointerIsNil(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Backup == nil {
+			return true, true
+		}
+		return (*(*v).Backup) == nil, true
+	}()
+	return ok2 && r1
+}
+
+// StringIsNil reports whether v matches the bexpr expression
+//
+//	Name is nil
+func StringIsNil(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// PointerEqual reports whether v matches the bexpr expression
+//
+//	Nick == "n"
+func PointerEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Nick == nil {
+			return false, false
+		}
+		return string((*(*v).Nick)) == "n", true
+	}()
+	return ok2 && r1
+}
+
+// PointerNotEqual reports whether v matches the bexpr expression
+//
+//	Nick != "n"
+func PointerNotEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Nick == nil {
+			return false, false
+		}
+		return !(string((*(*v).Nick)) == "n"), true
+	}()
+	return ok2 && r1
+}
+
+// PointerField reports whether v matches the bexpr expression
+//
+//	Owner.Name == "alice"
+func PointerField(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Owner == nil {
+			return false, false
+		}
+		return string((*(*v).Owner).Name) == "alice", true
+	}()
+	return ok2 && r1
+}
+
+// PointerPointerField reports whether v matches the bexpr expression
+//
+//	Backup.Name == "bob"
+func PointerPointerField(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Backup == nil {
+			return false, false
+		}
+		if (*(*v).Backup) == nil {
+			return false, false
+		}
+		return string((*(*(*v).Backup)).Name) == "bob", true
+	}()
+	return ok2 && r1
+}
+
+// Index reports whether v matches the bexpr expression
+//
+//	Tags.1 == "b"
+func Index(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if 1 >= len((*v).Tags) {
+			return false, false
+		}
+		return string((*v).Tags[1]) == "b", true
+	}()
+	return ok2 && r1
+}
+
+// IndexHex reports whether v matches the bexpr expression
+//
+//	"/Tags/0x1" == "b"
+func IndexHex(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if 1 >= len((*v).Tags) {
+			return false, false
+		}
+		return string((*v).Tags[1]) == "b", true
+	}()
+	return ok2 && r1
+}
+
+// IndexOutOfRange reports whether v matches the bexpr expression
+//
+//	Tags.5 != "b"
+func IndexOutOfRange(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if 5 >= len((*v).Tags) {
+			return false, false
+		}
+		return !(string((*v).Tags[5]) == "b"), true
+	}()
+	return ok2 && r1
+}
+
+// ArrayIndex reports whether v matches the bexpr expression
+//
+//	Ports.2 == 443
+func ArrayIndex(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if 2 >= len((*v).Ports) {
+			return false, false
+		}
+		return int64((*v).Ports[2]) == 443, true
+	}()
+	return ok2 && r1
+}
+
+// ArrayIndexOutOfRange reports whether v matches the bexpr expression
+//
+//	Ports.3 == 443
+func ArrayIndexOutOfRange(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// InvalidIndex reports whether v matches the bexpr expression
+//
+//	Tags.x == "b"
+func InvalidIndex(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// PointerElementIndex reports whether v matches the bexpr expression
+//
+//	Scores.0 == 7
+func PointerElementIndex(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if 0 >= len((*v).Scores) {
+			return false, false
+		}
+		if (*v).Scores[0] == nil {
+			return false, false
+		}
+		return int64((*(*v).Scores[0])) == 7, true
+	}()
+	return ok2 && r1
+}
+
+// StructInSlice reports whether v matches the bexpr expression
+//
+//	Items.0.Name == "first"
+func StructInSlice(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if 0 >= len((*v).Items) {
+			return false, false
+		}
+		return string((*v).Items[0].Name) == "first", true
+	}()
+	return ok2 && r1
+}
+
+// MapKey reports whether v matches the bexpr expression
+//
+//	Labels.env == "prod"
+func MapKey(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Labels["env"]
+		if !found4 {
+			return false, true
+		}
+		return string(x3) == "prod", true
+	}()
+	return ok2 && r1
+}
+
+// MissingKey reports whether v matches the bexpr expression
+//
+//	Labels.missing == "prod"
+func MissingKey(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Labels["missing"]
+		if !found4 {
+			return false, true
+		}
+		return string(x3) == "prod", true
+	}()
+	return ok2 && r1
+}
+
+// MissingKeyNotEqual reports whether v matches the bexpr expression
+//
+//	Labels.missing != "prod"
+func MissingKeyNotEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Labels["missing"]
+		if !found4 {
+			return true, true
+		}
+		return !(string(x3) == "prod"), true
+	}()
+	return ok2 && r1
+}
+
+// MissingKeyIsEmpty reports whether v matches the bexpr expression
+//
+//	Labels.missing is empty
+func MissingKeyIsEmpty(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Labels["missing"]
+		if !found4 {
+			return true, true
+		}
+		return len(x3) == 0, true
+	}()
+	return ok2 && r1
+}
+
+// MissingKeyNotIn reports whether v matches the bexpr expression
+//
+//	"a" not in Labels.missing
+func MissingKeyNotIn(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Labels["missing"]
+		if !found4 {
+			return true, true
+		}
+		return !(strings.Contains(string(x3), "a")), true
+	}()
+	return ok2 && r1
+}
+
+// MissingNestedKey reports whether v matches the bexpr expression
+//
+//	Meta.a.b == "c"
+func MissingNestedKey(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Meta["a"]
+		if !found4 {
+			return false, false
+		}
+		x5, found6 := x3["b"]
+		if !found6 {
+			return false, true
+		}
+		return string(x5) == "c", true
+	}()
+	return ok2 && r1
+}
+
+// MissingNestedKeyNotEqual reports whether v matches the bexpr expression
+//
+//	Meta.missing.b != "c"
+func MissingNestedKeyNotEqual(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Meta["missing"]
+		if !found4 {
+			return false, false
+		}
+		x5, found6 := x3["b"]
+		if !found6 {
+			return true, true
+		}
+		return !(string(x5) == "c"), true
+	}()
+	return ok2 && r1
+}
+
+// IntKey reports whether v matches the bexpr expression
+//
+//	Codes.200 == "ok"
+func IntKey(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Codes[200]
+		if !found4 {
+			return false, true
+		}
+		return string(x3) == "ok", true
+	}()
+	return ok2 && r1
+}
+
+// IntKeyInvalid reports whether v matches the bexpr expression
+//
+//	Codes.ok == "ok"
+func IntKeyInvalid(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// NamedKey reports whether v matches the bexpr expression
+//
+//	NamedKeys.a == "b"
+func NamedKey(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).NamedKeys[Key("a")]
+		if !found4 {
+			return false, true
+		}
+		return string(x3) == "b", true
+	}()
+	return ok2 && r1
+}
+
+// MissingKeyBehindPointer reports whether v matches the bexpr expression
+//
+//	MetaPtr.missing != "a"
+func MissingKeyBehindPointer(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).MetaPtr == nil {
+			return false, false
+		}
+		x3, found4 := (*(*v).MetaPtr)["missing"]
+		if !found4 {
+			return false, false
+		}
+		return !(string(x3) == "a"), true
+	}()
+	return ok2 && r1
+}
+
+// KeyBehindPointer reports whether v matches the bexpr expression
+//
+//	MetaPtr.a == "b"
+func KeyBehindPointer(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).MetaPtr == nil {
+			return false, false
+		}
+		x3, found4 := (*(*v).MetaPtr)["a"]
+		if !found4 {
+			return false, false
+		}
+		return string(x3) == "b", true
+	}()
+	return ok2 && r1
+}
+
+// TopLevelJSONPointer reports whether v matches the bexpr expression
+//
+//	"/Owner/Attrs/team" == "core"
+func TopLevelJSONPointer(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Owner == nil {
+			return false, false
+		}
+		x3, found4 := (*(*v).Owner).Attrs["team"]
+		if !found4 {
+			return false, true
+		}
+		return string(x3) == "core", true
+	}()
+	return ok2 && r1
+}
+
+// MissingKeyBehindNilPointer reports whether v matches the bexpr expression
+//
+//	Owner.Attrs.team != "core"
+func MissingKeyBehindNilPointer(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if (*v).Owner == nil {
+			return false, false
+		}
+		x3, found4 := (*(*v).Owner).Attrs["team"]
+		if !found4 {
+			return true, true
+		}
+		return !(string(x3) == "core"), true
+	}()
+	return ok2 && r1
+}
+
+// FieldOfString reports whether v matches the bexpr expression
+//
+//	Name.First == "a"
+func FieldOfString(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// And reports whether v matches the bexpr expression
+//
+//	Port == 80 and Name == "web"
+func And(v *Record) bool {
+	r3, ok4 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return int64((*v).Port) == 80, true
+	}()
+	r1, ok2 := r3, ok4
+	if ok4 && r3 {
+		r5, ok6 := func() (bool, bool) {
+			if v == nil {
+				return false, false
+			}
+			return string((*v).Name) == "web", true
+		}()
+		r1, ok2 = r5, ok6
+	}
+	return ok2 && r1
+}
+
+// Or reports whether v matches the bexpr expression
+//
+//	Port == 80 or Name == "web"
+func Or(v *Record) bool {
+	r3, ok4 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return int64((*v).Port) == 80, true
+	}()
+	r1, ok2 := r3, ok4
+	if ok4 && !r3 {
+		r5, ok6 := func() (bool, bool) {
+			if v == nil {
+				return false, false
+			}
+			return string((*v).Name) == "web", true
+		}()
+		r1, ok2 = r5, ok6
+	}
+	return ok2 && r1
+}
+
+// Not reports whether v matches the bexpr expression
+//
+//	not Enabled == true
+func Not(v *Record) bool {
+	r3, ok4 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return bool((*v).Enabled) == true, true
+	}()
+	r1, ok2 := !r3, ok4
+	return ok2 && r1
+}
+
+// NotError reports whether v matches the bexpr expression
+//
+//	not Port == "http"
+func NotError(v *Record) bool {
+	r3, ok4 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	r1, ok2 := !r3, ok4
+	return ok2 && r1
+}
+
+// ErrorShortCircuit reports whether v matches the bexpr expression
+//
+//	Port == 1 and Port == "http"
+func ErrorShortCircuit(v *Record) bool {
+	r3, ok4 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return int64((*v).Port) == 1, true
+	}()
+	r1, ok2 := r3, ok4
+	if ok4 && r3 {
+		r5, ok6 := func() (bool, bool) {
+			if v == nil {
+				return false, false
+			}
+			return false, false
+		}()
+		r1, ok2 = r5, ok6
+	}
+	return ok2 && r1
+}
+
+// ErrorOr reports whether v matches the bexpr expression
+//
+//	Port == "http" or Port == 80
+func ErrorOr(v *Record) bool {
+	r3, ok4 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	r1, ok2 := r3, ok4
+	if ok4 && !r3 {
+		r5, ok6 := func() (bool, bool) {
+			if v == nil {
+				return false, false
+			}
+			return int64((*v).Port) == 80, true
+		}()
+		r1, ok2 = r5, ok6
+	}
+	return ok2 && r1
+}
+
+// Any reports whether v matches the bexpr expression
+//
+//	any Tags as tag { tag == "b" }
+func Any(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Tags {
+			r4, ok5 := func() (bool, bool) {
+				return string((*v).Tags[i3]) == "b", true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// All reports whether v matches the bexpr expression
+//
+//	all Tags as tag { tag != "b" }
+func All(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Tags {
+			r4, ok5 := func() (bool, bool) {
+				return !(string((*v).Tags[i3]) == "b"), true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if !r4 {
+				return false, true
+			}
+		}
+		return true, true
+	}()
+	return ok2 && r1
+}
+
+// AnyIndex reports whether v matches the bexpr expression
+//
+//	any Tags as i, tag { i == 1 and tag == "b" }
+func AnyIndex(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Tags {
+			r6, ok7 := func() (bool, bool) {
+				return int64(i3) == 1, true
+			}()
+			r4, ok5 := r6, ok7
+			if ok7 && r6 {
+				r8, ok9 := func() (bool, bool) {
+					return string((*v).Tags[i3]) == "b", true
+				}()
+				r4, ok5 = r8, ok9
+			}
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// AnyIndexOnly reports whether v matches the bexpr expression
+//
+//	any Tags as i, _ { i == 2 }
+func AnyIndexOnly(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Tags {
+			r4, ok5 := func() (bool, bool) {
+				return int64(i3) == 2, true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// AnyUnused reports whether v matches the bexpr expression
+//
+//	any Tags as tag { Port == 80 }
+func AnyUnused(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for range (*v).Tags {
+			r4, ok5 := func() (bool, bool) {
+				if v == nil {
+					return false, false
+				}
+				return int64((*v).Port) == 80, true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// AnyArray reports whether v matches the bexpr expression
+//
+//	any Ports as p { p == 443 }
+func AnyArray(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Ports {
+			r4, ok5 := func() (bool, bool) {
+				return int64((*v).Ports[i3]) == 443, true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// AnyStruct reports whether v matches the bexpr expression
+//
+//	any Items as item { item.Name == "second" and item.id == 2 }
+func AnyStruct(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Items {
+			r6, ok7 := func() (bool, bool) {
+				return string((*v).Items[i3].Name) == "second", true
+			}()
+			r4, ok5 := r6, ok7
+			if ok7 && r6 {
+				r8, ok9 := func() (bool, bool) {
+					return int64((*v).Items[i3].ID) == 2, true
+				}()
+				r4, ok5 = r8, ok9
+			}
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// AnyPointers reports whether v matches the bexpr expression
+//
+//	any Refs as ref { ref.Name == "second" }
+func AnyPointers(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Refs {
+			r4, ok5 := func() (bool, bool) {
+				if (*v).Refs[i3] == nil {
+					return false, false
+				}
+				return string((*(*v).Refs[i3]).Name) == "second", true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// AllPointers reports whether v matches the bexpr expression
+//
+//	all Refs as ref { ref.Name != "third" }
+func AllPointers(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Refs {
+			r4, ok5 := func() (bool, bool) {
+				if (*v).Refs[i3] == nil {
+					return false, false
+				}
+				return !(string((*(*v).Refs[i3]).Name) == "third"), true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if !r4 {
+				return false, true
+			}
+		}
+		return true, true
+	}()
+	return ok2 && r1
+}
+
+// AnyMapKey reports whether v matches the bexpr expression
+//
+//	any Labels as k { k == "env" }
+func AnyMapKey(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for k3 := range (*v).Labels {
+			r4, ok5 := func() (bool, bool) {
+				return string(k3) == "env", true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// AnyMapValue reports whether v matches the bexpr expression
+//
+//	any Labels as _, v { v == "prod" }
+func AnyMapValue(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for k3 := range (*v).Labels {
+			r4, ok5 := func() (bool, bool) {
+				return string((*v).Labels[k3]) == "prod", true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// AllMapKeyValue reports whether v matches the bexpr expression
+//
+//	all Labels as k, v { k != "env" or v == "prod" }
+func AllMapKeyValue(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for k3 := range (*v).Labels {
+			r6, ok7 := func() (bool, bool) {
+				return !(string(k3) == "env"), true
+			}()
+			r4, ok5 := r6, ok7
+			if ok7 && !r6 {
+				r8, ok9 := func() (bool, bool) {
+					return string((*v).Labels[k3]) == "prod", true
+				}()
+				r4, ok5 = r8, ok9
+			}
+			if !ok5 {
+				return false, false
+			}
+			if !r4 {
+				return false, true
+			}
+		}
+		return true, true
+	}()
+	return ok2 && r1
+}
+
+// MapKeyField reports whether v matches the bexpr expression
+//
+//	any Labels as k { k.First == "a" }
+func MapKeyField(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for range (*v).Labels {
+			r4, ok5 := func() (bool, bool) {
+				return false, false
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// BindingConflict reports whether v matches the bexpr expression
+//
+//	all Labels as k, k { Port == 80 }
+func BindingConflict(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for range (*v).Labels {
+			return false, false
+		}
+		return true, true
+	}()
+	return ok2 && r1
+}
+
+// IntKeyCollection reports whether v matches the bexpr expression
+//
+//	any Codes as k { k == "ok" }
+func IntKeyCollection(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// NotCollection reports whether v matches the bexpr expression
+//
+//	any Name as c { c == "w" }
+func NotCollection(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// MissingCollection reports whether v matches the bexpr expression
+//
+//	all Meta.missing as v { v == "a" }
+func MissingCollection(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Meta["missing"]
+		if !found4 {
+			return true, true
+		}
+		for k5 := range x3 {
+			r6, ok7 := func() (bool, bool) {
+				return string(k5) == "a", true
+			}()
+			if !ok7 {
+				return false, false
+			}
+			if !r6 {
+				return false, true
+			}
+		}
+		return true, true
+	}()
+	return ok2 && r1
+}
+
+// MissingCollectionAny reports whether v matches the bexpr expression
+//
+//	any Meta.missing as v { v == "a" }
+func MissingCollectionAny(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		x3, found4 := (*v).Meta["missing"]
+		if !found4 {
+			return false, true
+		}
+		for k5 := range x3 {
+			r6, ok7 := func() (bool, bool) {
+				return string(k5) == "a", true
+			}()
+			if !ok7 {
+				return false, false
+			}
+			if r6 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// Nested reports whether v matches the bexpr expression
+//
+//	any Items as item { any item.Tags as tag { tag == "y" } }
+func Nested(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Items {
+			r4, ok5 := func() (bool, bool) {
+				for i6 := range (*v).Items[i3].Tags {
+					r7, ok8 := func() (bool, bool) {
+						return string((*v).Items[i3].Tags[i6]) == "y", true
+					}()
+					if !ok8 {
+						return false, false
+					}
+					if r7 {
+						return true, true
+					}
+				}
+				return false, true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// NestedMap reports whether v matches the bexpr expression
+//
+//	all Groups as name, items { any items as item { item.Name == name } }
+func NestedMap(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for k3 := range (*v).Groups {
+			r4, ok5 := func() (bool, bool) {
+				for i6 := range (*v).Groups[k3] {
+					r7, ok8 := func() (bool, bool) {
+						return string((*v).Groups[k3][i6].Name) == "name", true
+					}()
+					if !ok8 {
+						return false, false
+					}
+					if r7 {
+						return true, true
+					}
+				}
+				return false, true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if !r4 {
+				return false, true
+			}
+		}
+		return true, true
+	}()
+	return ok2 && r1
+}
+
+// NestedMissingKey reports whether v matches the bexpr expression
+//
+//	any Items as item { item.Attrs.missing != "a" }
+func NestedMissingKey(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Items {
+			r4, ok5 := func() (bool, bool) {
+				x6, found7 := (*v).Items[i3].Attrs["missing"]
+				if !found7 {
+					return true, true
+				}
+				return !(string(x6) == "a"), true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// NestedShadowing reports whether v matches the bexpr expression
+//
+//	any Items as x { any x.Tags as x { x == "y" } }
+func NestedShadowing(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Items {
+			r4, ok5 := func() (bool, bool) {
+				for i6 := range (*v).Items[i3].Tags {
+					r7, ok8 := func() (bool, bool) {
+						return string((*v).Items[i3].Tags[i6]) == "y", true
+					}()
+					if !ok8 {
+						return false, false
+					}
+					if r7 {
+						return true, true
+					}
+				}
+				return false, true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// NestedOuterBinding reports whether v matches the bexpr expression
+//
+//	any Items as item { any item.Tags as tag { item.Name == "first" and tag == "y" } }
+func NestedOuterBinding(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Items {
+			r4, ok5 := func() (bool, bool) {
+				for i6 := range (*v).Items[i3].Tags {
+					r9, ok10 := func() (bool, bool) {
+						return string((*v).Items[i3].Name) == "first", true
+					}()
+					r7, ok8 := r9, ok10
+					if ok10 && r9 {
+						r11, ok12 := func() (bool, bool) {
+							return string((*v).Items[i3].Tags[i6]) == "y", true
+						}()
+						r7, ok8 = r11, ok12
+					}
+					if !ok8 {
+						return false, false
+					}
+					if r7 {
+						return true, true
+					}
+				}
+				return false, true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// ElementMissingKey reports whether v matches the bexpr expression
+//
+//	any Meta as _, m { m.b != "c" }
+func ElementMissingKey(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for k3 := range (*v).Meta {
+			r4, ok5 := func() (bool, bool) {
+				x6, found7 := (*v).Meta[k3]["b"]
+				if !found7 {
+					return true, true
+				}
+				return !(string(x6) == "c"), true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// ElementPointerField reports whether v matches the bexpr expression
+//
+//	any Items as item { item.Owner.Name == "alice" }
+func ElementPointerField(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Items {
+			r4, ok5 := func() (bool, bool) {
+				if (*v).Items[i3].Owner == nil {
+					return false, false
+				}
+				return string((*(*v).Items[i3].Owner).Name) == "alice", true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if r4 {
+				return true, true
+			}
+		}
+		return false, true
+	}()
+	return ok2 && r1
+}
+
+// ElementIsNil reports whether v matches the bexpr expression
+//
+//	all Items as item { item.Owner is nil }
+func ElementIsNil(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		for i3 := range (*v).Items {
+			r4, ok5 := func() (bool, bool) {
+				if (*v).Items[i3].Owner == nil {
+					return true, true
+				}
+				return false, true
+			}()
+			if !ok5 {
+				return false, false
+			}
+			if !r4 {
+				return false, true
+			}
+		}
+		return true, true
+	}()
+	return ok2 && r1
+}
+
+// OneOf reports whether v matches its bexpr expression
+func OneOf(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return int64((*v).Port) == 22 || int64((*v).Port) == 80, true
+	}()
+	return ok2 && r1
+}
+
+// NotOneOf reports whether v matches its bexpr expression
+func NotOneOf(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		return !(string((*v).Name) == "web" || string((*v).Name) == "db"), true
+	}()
+	return ok2 && r1
+}
+
+// OneOfInvalid reports whether v matches its bexpr expression
+func OneOfInvalid(v *Record) bool {
+	r1, ok2 := func() (bool, bool) {
+		if v == nil {
+			return false, false
+		}
+		if int64((*v).Port) == 80 {
+			return true, true
+		}
+		return false, false
+	}()
+	return ok2 && r1
+}
+
+// Constant reports whether v matches its bexpr expression
+func Constant(v *Record) bool {
+	r3, ok4 := true, true
+	r1, ok2 := r3, ok4
+	if ok4 && !r3 {
+		r5, ok6 := func() (bool, bool) {
+			if v == nil {
+				return false, false
+			}
+			return false, false
+		}()
+		r1, ok2 = r5, ok6
+	}
+	return ok2 && r1
+}
+
+var generated = map[string]func(*Record) bool{
+	"Equal":                      Equal,
+	"NotEqual":                   NotEqual,
+	"TaggedField":                TaggedField,
+	"IntEqual":                   IntEqual,
+	"IntHex":                     IntHex,
+	"IntInvalid":                 IntInvalid,
+	"FloatEqual":                 FloatEqual,
+	"FloatNaN":                   FloatNaN,
+	"FloatInf":                   FloatInf,
+	"Float32Equal":               Float32Equal,
+	"UintEqual":                  UintEqual,
+	"UintNegative":               UintNegative,
+	"BoolEqual":                  BoolEqual,
+	"BoolInvalid":                BoolInvalid,
+	"NamedInt":                   NamedInt,
+	"BytesMatches":               BytesMatches,
+	"Matches":                    Matches,
+	"NotMatches":                 NotMatches,
+	"MatchesInt":                 MatchesInt,
+	"StringIn":                   StringIn,
+	"StringNotIn":                StringNotIn,
+	"SliceIn":                    SliceIn,
+	"SliceNotIn":                 SliceNotIn,
+	"ArrayIn":                    ArrayIn,
+	"ArrayInInvalid":             ArrayInInvalid,
+	"MapIn":                      MapIn,
+	"MapNotIn":                   MapNotIn,
+	"InterfaceMapIn":             InterfaceMapIn,
+	"IntIn":                      IntIn,
+	"IsEmpty":                    IsEmpty,
+	"IsNotEmpty":                 IsNotEmpty,
+	"StringIsEmpty":              StringIsEmpty,
+	"IntIsEmpty":                 IntIsEmpty,
+	"ChanIsEmpty":                ChanIsEmpty,
+	"IsNil":                      IsNil,
+	"IsNotNil":                   IsNotNil,
+	"SliceIsNil":                 SliceIsNil,
+	"PointerPointerIsNil":        PointerPointerIsNil,
+	"StringIsNil":                StringIsNil,
+	"PointerEqual":               PointerEqual,
+	"PointerNotEqual":            PointerNotEqual,
+	"PointerField":               PointerField,
+	"PointerPointerField":        PointerPointerField,
+	"Index":                      Index,
+	"IndexHex":                   IndexHex,
+	"IndexOutOfRange":            IndexOutOfRange,
+	"ArrayIndex":                 ArrayIndex,
+	"ArrayIndexOutOfRange":       ArrayIndexOutOfRange,
+	"InvalidIndex":               InvalidIndex,
+	"PointerElementIndex":        PointerElementIndex,
+	"StructInSlice":              StructInSlice,
+	"MapKey":                     MapKey,
+	"MissingKey":                 MissingKey,
+	"MissingKeyNotEqual":         MissingKeyNotEqual,
+	"MissingKeyIsEmpty":          MissingKeyIsEmpty,
+	"MissingKeyNotIn":            MissingKeyNotIn,
+	"MissingNestedKey":           MissingNestedKey,
+	"MissingNestedKeyNotEqual":   MissingNestedKeyNotEqual,
+	"IntKey":                     IntKey,
+	"IntKeyInvalid":              IntKeyInvalid,
+	"NamedKey":                   NamedKey,
+	"MissingKeyBehindPointer":    MissingKeyBehindPointer,
+	"KeyBehindPointer":           KeyBehindPointer,
+	"TopLevelJSONPointer":        TopLevelJSONPointer,
+	"MissingKeyBehindNilPointer": MissingKeyBehindNilPointer,
+	"FieldOfString":              FieldOfString,
+	"And":                        And,
+	"Or":                         Or,
+	"Not":                        Not,
+	"NotError":                   NotError,
+	"ErrorShortCircuit":          ErrorShortCircuit,
+	"ErrorOr":                    ErrorOr,
+	"Any":                        Any,
+	"All":                        All,
+	"AnyIndex":                   AnyIndex,
+	"AnyIndexOnly":               AnyIndexOnly,
+	"AnyUnused":                  AnyUnused,
+	"AnyArray":                   AnyArray,
+	"AnyStruct":                  AnyStruct,
+	"AnyPointers":                AnyPointers,
+	"AllPointers":                AllPointers,
+	"AnyMapKey":                  AnyMapKey,
+	"AnyMapValue":                AnyMapValue,
+	"AllMapKeyValue":             AllMapKeyValue,
+	"MapKeyField":                MapKeyField,
+	"BindingConflict":            BindingConflict,
+	"IntKeyCollection":           IntKeyCollection,
+	"NotCollection":              NotCollection,
+	"MissingCollection":          MissingCollection,
+	"MissingCollectionAny":       MissingCollectionAny,
+	"Nested":                     Nested,
+	"NestedMap":                  NestedMap,
+	"NestedMissingKey":           NestedMissingKey,
+	"NestedShadowing":            NestedShadowing,
+	"NestedOuterBinding":         NestedOuterBinding,
+	"ElementMissingKey":          ElementMissingKey,
+	"ElementPointerField":        ElementPointerField,
+	"ElementIsNil":               ElementIsNil,
+	"OneOf":                      OneOf,
+	"NotOneOf":                   NotOneOf,
+	"OneOfInvalid":               OneOfInvalid,
+	"Constant":                   Constant,
+}
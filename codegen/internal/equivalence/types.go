@@ -0,0 +1,57 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package equivalence checks that the functions generated by codegen match
+// what Evaluate returns for the same expressions. generated.go is updated by
+// running its tests with -update.
+package equivalence
+
+type Level int
+
+type Key string
+
+type Owner struct {
+	Name   string
+	Emails []string
+	Attrs  map[string]string
+}
+
+type Item struct {
+	ID    int `bexpr:"id"`
+	Name  string
+	Tags  []string
+	Attrs map[string]string
+	Owner *Owner
+}
+
+type Record struct {
+	Name    string
+	Alias   string `bexpr:"nick"`
+	Hidden  string `bexpr:"-"`
+	Port    int
+	Weight  float64
+	Ratio   float32
+	Count   uint16
+	Enabled bool
+	Level   Level
+	Raw     []byte
+
+	Tags      []string
+	Ports     [3]int
+	Scores    []*int
+	Labels    map[string]string
+	Counters  map[string]int
+	Codes     map[int]string
+	NamedKeys map[Key]string
+	Meta      map[string]map[string]string
+	MetaPtr   *map[string]string
+	Any       map[interface{}]string
+
+	Owner  *Owner
+	Backup **Owner
+	Nick   *string
+	Items  []Item
+	Refs   []*Item
+	Groups map[string][]Item
+	Ch     chan int
+}
@@ -0,0 +1,60 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+)
+
+// Load type-checks the package in dir, ignoring the files listed in exclude
+// which usually includes the previously generated one. Imports are resolved
+// from the module of the working directory, which is the package directory
+// when running from go generate.
+func Load(dir string, exclude ...string) (*types.Package, error) {
+	bpkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find package in %s: %w", dir, err)
+	}
+
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[filepath.Base(name)] = true
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bpkg.GoFiles {
+		if excluded[name] {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(bpkg.ImportPath, fset, files, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to type-check package in %s: %w", dir, err)
+	}
+	return pkg, nil
+}
+
+// LookupType returns the type named name in pkg.
+func LookupType(pkg *types.Package, name string) (types.Type, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in package %s", name, pkg.Name())
+	}
+	return obj.Type(), nil
+}
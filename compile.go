@@ -0,0 +1,640 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// compiled is an expression turned into a tree of closures by compile. The
+// work that does not depend on the datum, like dispatching on the type of the
+// nodes, coercing literals and compiling regular expressions, is done once
+// when the Evaluator is created.
+type compiled func(datum interface{}, opts *options) (bool, error)
+
+// compiledNode is a node of the syntax tree along with its compiled form.
+// Evaluate runs the program of the root, while Explain, EvaluateThreeValued
+// and PartialEvaluate walk the nodes to reuse their matchers and programs.
+type compiledNode struct {
+	expression grammar.Expression
+	program    compiled
+	// children holds the operand of not expressions, the operands of and and
+	// or expressions, and the body of collection expressions
+	children []*compiledNode
+	// match is set for match expressions
+	match *matchNode
+}
+
+// The kinds of values literals are coerced to
+const (
+	literalString = iota
+	literalBool
+	literalInt
+	literalUint
+	literalFloat32
+	literalFloat64
+	literalKinds
+)
+
+func literalKind(kind reflect.Kind) int {
+	switch kind {
+	case reflect.Bool:
+		return literalBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return literalInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return literalUint
+	case reflect.Float32:
+		return literalFloat32
+	case reflect.Float64:
+		return literalFloat64
+	default:
+		return literalString
+	}
+}
+
+// literal is a match value coerced ahead of time to every kind it may be
+// compared with. Coercion errors are kept to be reported when a value of
+// that kind is actually met.
+type literal struct {
+	raw    string
+	values [literalKinds]interface{}
+	errs   [literalKinds]error
+}
+
+func newLiteral(value *grammar.MatchValue) *literal {
+	l := &literal{raw: value.Raw}
+	l.values[literalString] = value.Raw
+	l.values[literalBool], l.errs[literalBool] = CoerceBool(value.Raw)
+	l.values[literalInt], l.errs[literalInt] = CoerceInt64(value.Raw)
+	l.values[literalUint], l.errs[literalUint] = CoerceUint64(value.Raw)
+	l.values[literalFloat32], l.errs[literalFloat32] = CoerceFloat32(value.Raw)
+	l.values[literalFloat64], l.errs[literalFloat64] = CoerceFloat64(value.Raw)
+	return l
+}
+
+// value returns the literal coerced for kind
+func (l *literal) value(kind reflect.Kind) (interface{}, error) {
+	k := literalKind(kind)
+	return l.values[k], l.errs[k]
+}
+
+// compiler holds the settings of compile
+type compiler struct {
+	// static resolves selector paths to the indexes of the struct fields to
+	// follow from the datum when its type is known, see CompileFor. ok is
+	// false when the path has to be looked up dynamically and an error is
+	// returned for paths that can never be found.
+	static func(path []string) (fields []int, ok bool, err error)
+	// budgeted is true when the evaluations have a budget, their node visits
+	// are only counted then
+	budgeted bool
+}
+
+// compile turns ast into closures, it fails for the nodes that would be
+// rejected on every evaluation. The closures must not modify the options
+// they are given.
+func (c *compiler) compile(ast grammar.Expression) (*compiledNode, error) {
+	n, err := c.compileNode(ast)
+	if err != nil {
+		return nil, err
+	}
+	return c.budget(n), nil
+}
+
+// validate checks a syntax tree that was not produced by the parser, so the
+// nodes it is missing or whose operators are unknown are reported as errors
+// rather than making the compilation or the evaluations panic
+func validate(ast grammar.Expression) error {
+	return validateNode(ast, "expression")
+}
+
+// validateNode validates ast, what naming it in the error reported when it is
+// missing
+func validateNode(ast grammar.Expression, what string) error {
+	if v := reflect.ValueOf(ast); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return fmt.Errorf("invalid AST node: missing %s", what)
+	}
+
+	switch node := ast.(type) {
+	case *grammar.UnaryExpression:
+		if node.Operator != grammar.UnaryOpNot {
+			return fmt.Errorf("invalid unary operator: %d", node.Operator)
+		}
+		return validateNode(node.Operand, "operand of not expression")
+	case *grammar.BinaryExpression:
+		if node.Operator != grammar.BinaryOpAnd && node.Operator != grammar.BinaryOpOr {
+			return fmt.Errorf("invalid binary operator: %d", node.Operator)
+		}
+		op := strings.ToLower(node.Operator.String())
+		if err := validateNode(node.Left, "left operand of "+op+" expression"); err != nil {
+			return err
+		}
+		return validateNode(node.Right, "right operand of "+op+" expression")
+	case *grammar.MatchExpression:
+		switch node.Operator {
+		case grammar.MatchEqual, grammar.MatchNotEqual, grammar.MatchIn, grammar.MatchNotIn,
+			grammar.MatchMatches, grammar.MatchNotMatches:
+			if node.Value == nil {
+				return fmt.Errorf("invalid AST node: missing value of %s expression", strings.ToLower(node.Operator.String()))
+			}
+		case grammar.MatchIsOneOf, grammar.MatchIsNotOneOf:
+			for _, value := range node.Values {
+				if value == nil {
+					return fmt.Errorf("invalid AST node: missing value of %s expression", strings.ToLower(node.Operator.String()))
+				}
+			}
+		case grammar.MatchIsEmpty, grammar.MatchIsNotEmpty, grammar.MatchIsNil, grammar.MatchIsNotNil:
+		default:
+			return fmt.Errorf("invalid match operation: %d", node.Operator)
+		}
+	case *grammar.CollectionExpression:
+		if node.Op != grammar.CollectionOpAll && node.Op != grammar.CollectionOpAny {
+			return fmt.Errorf("invalid collection operator: %q", node.Op)
+		}
+		return validateNode(node.Inner, "body of "+strings.ToLower(string(node.Op))+" expression")
+	case *grammar.ConstantExpression:
+	default:
+		return fmt.Errorf("invalid AST node: %T", ast)
+	}
+	return nil
+}
+
+// budget makes the program of n count its visits when evaluations are
+// budgeted
+func (c *compiler) budget(n *compiledNode) *compiledNode {
+	if !c.budgeted {
+		return n
+	}
+	program := n.program
+	n.program = func(datum interface{}, opts *options) (bool, error) {
+		if err := opts.withBudget.visit(); err != nil {
+			return false, err
+		}
+		return program(datum, opts)
+	}
+	return n
+}
+
+func (c *compiler) compileNode(ast grammar.Expression) (*compiledNode, error) {
+	switch node := ast.(type) {
+	case *grammar.UnaryExpression:
+		if node.Operator != grammar.UnaryOpNot {
+			break
+		}
+		operand, err := c.compile(node.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return notNode(node, operand), nil
+	case *grammar.BinaryExpression:
+		if node.Operator != grammar.BinaryOpAnd && node.Operator != grammar.BinaryOpOr {
+			break
+		}
+		left, err := c.compile(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := c.compile(node.Right)
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode(node, left, right), nil
+	case *grammar.MatchExpression:
+		return c.compileMatchExpression(node)
+	case *grammar.CollectionExpression:
+		return c.compileCollectionExpression(node)
+	case *grammar.ConstantExpression:
+		return constantNode(node), nil
+	}
+	return nil, fmt.Errorf("invalid AST node")
+}
+
+func notNode(expression *grammar.UnaryExpression, operand *compiledNode) *compiledNode {
+	program := operand.program
+	return &compiledNode{
+		expression: expression,
+		children:   []*compiledNode{operand},
+		program: func(datum interface{}, opts *options) (bool, error) {
+			result, err := program(datum, opts)
+			return !result, err
+		},
+	}
+}
+
+// binaryNode compiles an and or or expression
+func binaryNode(expression *grammar.BinaryExpression, left, right *compiledNode) *compiledNode {
+	// The result of left deciding the result of the expression
+	decider := expression.Operator == grammar.BinaryOpOr
+	leftProgram, rightProgram := left.program, right.program
+	return &compiledNode{
+		expression: expression,
+		children:   []*compiledNode{left, right},
+		program: func(datum interface{}, opts *options) (bool, error) {
+			result, err := leftProgram(datum, opts)
+			if err != nil || result == decider {
+				return result, err
+			}
+			return rightProgram(datum, opts)
+		},
+	}
+}
+
+func constantNode(expression *grammar.ConstantExpression) *compiledNode {
+	value := expression.Value
+	return &compiledNode{
+		expression: expression,
+		program: func(interface{}, *options) (bool, error) {
+			return value, nil
+		},
+	}
+}
+
+// matcher checks a value found by a match expression
+type matcher func(value reflect.Value) (bool, error)
+
+func negateMatcher(m matcher) matcher {
+	return func(value reflect.Value) (bool, error) {
+		result, err := m(value)
+		if err == nil {
+			return !result, nil
+		}
+		return false, err
+	}
+}
+
+// matchNode is a compiled match expression
+type matchNode struct {
+	expression *grammar.MatchExpression
+	match      matcher
+	// regex is set when the values matched are checked against the budget of
+	// the evaluation for regular expressions
+	regex bool
+	// fields are the struct fields resolved by compiler.static, when static
+	// is set
+	fields []int
+	static bool
+}
+
+func (c *compiler) compileMatchExpression(expression *grammar.MatchExpression) (*compiledNode, error) {
+	var match matcher
+	switch expression.Operator {
+	case grammar.MatchEqual, grammar.MatchNotEqual:
+		match = compileEqual(newLiteral(expression.Value))
+	case grammar.MatchIn, grammar.MatchNotIn:
+		match = compileIn(expression, newLiteral(expression.Value))
+	case grammar.MatchIsEmpty, grammar.MatchIsNotEmpty:
+		match = func(value reflect.Value) (bool, error) {
+			return doMatchIsEmpty(expression, value)
+		}
+	case grammar.MatchMatches, grammar.MatchNotMatches:
+		var err error
+		if match, err = compileMatches(expression.Value.Raw); err != nil {
+			return nil, err
+		}
+	case grammar.MatchIsNil, grammar.MatchIsNotNil:
+		match = func(value reflect.Value) (bool, error) {
+			return doMatchIsNil(expression, value)
+		}
+	case grammar.MatchIsOneOf, grammar.MatchIsNotOneOf:
+		candidates := make([]matcher, 0, len(expression.Values))
+		for _, v := range expression.Values {
+			candidates = append(candidates, compileEqual(newLiteral(v)))
+		}
+		match = func(value reflect.Value) (bool, error) {
+			for _, candidate := range candidates {
+				result, err := candidate(value)
+				if err != nil || result {
+					return result, err
+				}
+			}
+			return false, nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid match operation: %d", expression.Operator)
+	}
+
+	switch expression.Operator {
+	case grammar.MatchNotEqual, grammar.MatchNotIn, grammar.MatchIsNotEmpty,
+		grammar.MatchNotMatches, grammar.MatchIsNotNil, grammar.MatchIsNotOneOf:
+		match = negateMatcher(match)
+	}
+
+	m := &matchNode{
+		expression: expression,
+		match:      match,
+		regex:      c.budgeted && (expression.Operator == grammar.MatchMatches || expression.Operator == grammar.MatchNotMatches),
+	}
+	if c.static != nil {
+		var err error
+		if m.fields, m.static, err = c.static(expression.Selector.Path); err != nil {
+			return nil, err
+		}
+	}
+	return &compiledNode{expression: expression, program: m.evaluate, match: m}, nil
+}
+
+// lookup returns the value referenced by the selector of the match expression,
+// prepared for its matcher. present is false when it references a missing map
+// key.
+func (m *matchNode) lookup(datum interface{}, opts *options) (value reflect.Value, present bool, err error) {
+	if m.static {
+		if value, ok := staticValue(datum, m.fields); ok {
+			if value.Kind() == reflect.Interface {
+				value = value.Elem()
+			}
+			if value.IsValid() && value.Type() == jsonNumberTyp {
+				value, err = matchedValue(value.Interface())
+				return value, true, err
+			}
+			return reflect.Indirect(value), true, nil
+		}
+		// Let pointerstructure report the nil pointer
+	}
+
+	val, present, err := lookupValue(datum, m.expression.Selector.Path, opts)
+	if err != nil || !present {
+		return reflect.Value{}, present, err
+	}
+	value, err = matchedValue(val)
+	return value, true, err
+}
+
+// matchValue matches a value returned by lookup
+func (m *matchNode) matchValue(value reflect.Value, opts *options) (bool, error) {
+	if m.regex {
+		if err := opts.withBudget.regexInput(value); err != nil {
+			return false, err
+		}
+	}
+	return m.match(value)
+}
+
+// evaluate is the program of the match expression
+func (m *matchNode) evaluate(datum interface{}, opts *options) (bool, error) {
+	value, present, err := m.lookup(datum, opts)
+	if err != nil {
+		return false, err
+	}
+	if !present {
+		return m.expression.Operator.NotPresentDisposition(), nil
+	}
+	return m.matchValue(value, opts)
+}
+
+var jsonNumberTyp = reflect.TypeOf(json.Number(""))
+
+// matchedValue prepares a value found by a match expression for its matcher
+func matchedValue(val interface{}) (reflect.Value, error) {
+	if jn, ok := val.(json.Number); ok {
+		if jni, err := jn.Int64(); err == nil {
+			val = jni
+		} else if jnf, err := jn.Float64(); err == nil {
+			val = jnf
+		} else {
+			return reflect.Value{}, fmt.Errorf("unable to convert json number %s to int or float", jn)
+		}
+	}
+	return reflect.Indirect(reflect.ValueOf(val)), nil
+}
+
+// staticValue follows the struct fields resolved by compiler.static from
+// datum, a pointer to the value being evaluated. ok is false if a nil pointer
+// is met on the way.
+func staticValue(datum interface{}, fields []int) (reflect.Value, bool) {
+	v := reflect.ValueOf(datum)
+	for _, field := range fields {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(field)
+	}
+	return v, true
+}
+
+func compileEqual(lit *literal) matcher {
+	return func(value reflect.Value) (bool, error) {
+		eqFn := primitiveEqualityFn(value.Kind())
+		if eqFn == nil {
+			return false, errors.New("unable to find suitable primitive comparison function for matching")
+		}
+		matchValue, err := lit.value(value.Kind())
+		if err != nil {
+			return false, fmt.Errorf("error getting match value in expression: %w", err)
+		}
+		return eqFn(matchValue, value), nil
+	}
+}
+
+func compileIn(expression *grammar.MatchExpression, lit *literal) matcher {
+	key := reflect.ValueOf(lit.raw)
+	return func(value reflect.Value) (bool, error) {
+		if _, err := lit.value(value.Kind()); err != nil {
+			return false, fmt.Errorf("error getting match value in expression: %w", err)
+		}
+
+		switch kind := value.Kind(); kind {
+		case reflect.Map:
+			return value.MapIndex(key).IsValid(), nil
+
+		case reflect.Slice, reflect.Array:
+			kind := derefType(value.Type().Elem()).Kind()
+			if kind == reflect.Interface {
+				// The elements of interface slices can be of any type, so
+				// the literal is coerced for each of them. Since it
+				// cannot be coerced to every type, the elements it cannot
+				// be coerced for, like `"true" in Slice` against an
+				// integer, are skipped rather than reported as errors.
+				for i := 0; i < value.Len(); i++ {
+					item := value.Index(i).Elem()
+					kind := derefType(item.Type()).Kind()
+					matchValue, err := lit.value(kind)
+					if err != nil {
+						if errors.Is(err, strconv.ErrSyntax) {
+							continue
+						}
+						return false, errors.New(`error getting interface slice match value in expression`)
+					}
+					eqFn := primitiveEqualityFn(kind)
+					if eqFn == nil {
+						return false, fmt.Errorf(`unable to find suitable primitive comparison function for "in" comparison in interface slice: %s`, kind)
+					}
+					if eqFn(matchValue, reflect.Indirect(item)) {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+
+			matchValue, err := lit.value(kind)
+			if err != nil {
+				return false, fmt.Errorf("error getting match value in expression: %w", err)
+			}
+			eqFn := primitiveEqualityFn(kind)
+			if eqFn == nil {
+				return false, errors.New(`unable to find suitable primitive comparison function for "in" comparison`)
+			}
+			for i := 0; i < value.Len(); i++ {
+				if eqFn(matchValue, reflect.Indirect(value.Index(i))) {
+					return true, nil
+				}
+			}
+			return false, nil
+
+		case reflect.String:
+			return strings.Contains(value.String(), lit.raw), nil
+
+		default:
+			return false, fmt.Errorf("cannot perform in/contains operations on type %s for selector: %q", kind, expression.Selector)
+		}
+	}
+}
+
+func compileMatches(pattern string) (matcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile regular expression %q: %v", pattern, err)
+	}
+	return func(value reflect.Value) (bool, error) {
+		if !value.Type().ConvertibleTo(byteSliceTyp) {
+			return false, fmt.Errorf("value of type %s is not convertible to []byte", value.Type())
+		}
+		return re.Match(value.Convert(byteSliceTyp).Interface().([]byte)), nil
+	}, nil
+}
+
+func (c *compiler) compileCollectionExpression(expression *grammar.CollectionExpression) (*compiledNode, error) {
+	if c.static != nil {
+		// The collection itself is looked up dynamically but its selector
+		// can still be validated
+		if _, _, err := c.static(expression.Selector.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	// The selectors of the body reference the local variables of the
+	// collection and are always resolved dynamically
+	innerCompiler := compiler{budgeted: c.budgeted}
+	inner, err := innerCompiler.compile(expression.Inner)
+	if err != nil {
+		return nil, err
+	}
+
+	innerProgram := inner.program
+	isAll := expression.Op == grammar.CollectionOpAll
+	program := func(datum interface{}, opts *options) (bool, error) {
+		result := isAll
+		_, err := iterateCollection(expression, datum, opts, func(innerOpts *options, _ []reflect.Value, _ int) (bool, error) {
+			match, err := innerProgram(datum, innerOpts)
+			if err != nil || match != isAll {
+				result = match
+				return true, err
+			}
+			return false, nil
+		})
+		if err != nil {
+			return false, err
+		}
+		return result, nil
+	}
+	return &compiledNode{expression: expression, program: program, children: []*compiledNode{inner}}, nil
+}
+
+// iterateCollection calls fn for each element of the collection of expression
+// with the options holding the local variables of the element i, keys being
+// the keys of the collection when it is a map. It stops when fn returns true
+// and reports whether the collection was present.
+func iterateCollection(expression *grammar.CollectionExpression, datum interface{}, opts *options, fn func(innerOpts *options, keys []reflect.Value, i int) (bool, error)) (bool, error) {
+	path := expression.Selector.Path
+	val, present, err := lookupValue(datum, path, opts)
+	if err != nil || !present {
+		return present, err
+	}
+
+	v := reflect.ValueOf(val)
+
+	var keys []reflect.Value
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key() != reflect.TypeOf("") {
+			return true, fmt.Errorf("%s can only iterate over maps indexed with strings", expression.Op)
+		}
+		keys = v.MapKeys()
+	case reflect.Slice, reflect.Array:
+	default:
+		return true, fmt.Errorf(`%s is not a list or a map`, expression.Selector.String())
+	}
+
+	binding := expression.NameBinding
+	conflict := binding.Mode == grammar.CollectionBindIndexAndValue && binding.Index == binding.Value
+
+	// Each iteration adds its local variables on top of the ones of the
+	// enclosing collections, without touching them
+	locals := opts.withLocalVariables[:len(opts.withLocalVariables):len(opts.withLocalVariables)]
+	innerOpts := *opts
+	for i := 0; i < v.Len(); i++ {
+		if err := opts.withInterrupt.check(); err != nil {
+			return true, err
+		}
+		if err := opts.withBudget.iterate(); err != nil {
+			return true, err
+		}
+		if conflict {
+			return true, fmt.Errorf("%q cannot be used as a placeholder for both the index and the value", binding.Index)
+		}
+
+		innerOpts.withLocalVariables = elementLocals(locals, binding, path, v, keys, i)
+		if done, err := fn(&innerOpts, keys, i); done || err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// elementLocals appends to locals the local variables of the iteration of a
+// collection expression over the element i of v, keys being the keys of v when
+// it is a map
+func elementLocals(locals []localVariable, binding grammar.CollectionNameBinding, path []string, v reflect.Value, keys []reflect.Value, i int) []localVariable {
+	if v.Kind() == reflect.Map {
+		key := keys[i]
+		if binding.Default != "" {
+			locals = append(locals, localVariable{name: binding.Default, value: key.Interface()})
+		}
+		if binding.Index != "" {
+			locals = append(locals, localVariable{name: binding.Index, value: key.Interface()})
+		}
+		if binding.Value != "" {
+			elem := make([]string, 0, len(path)+1)
+			elem = append(elem, path...)
+			elem = append(elem, key.Interface().(string))
+			locals = append(locals, localVariable{name: binding.Value, path: elem})
+		}
+		return locals
+	}
+
+	if binding.Index != "" {
+		locals = append(locals, localVariable{name: binding.Index, value: i})
+	}
+	elem := make([]string, 0, len(path)+1)
+	elem = append(elem, path...)
+	elem = append(elem, strconv.Itoa(i))
+	if binding.Default != "" {
+		locals = append(locals, localVariable{name: binding.Default, path: elem})
+	}
+	if binding.Value != "" {
+		locals = append(locals, localVariable{name: binding.Value, path: elem})
+	}
+	return locals
+}
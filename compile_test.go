@@ -0,0 +1,115 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompile checks the compiled expressions behave like the interpreter,
+// errors included
+func TestCompile(t *testing.T) {
+	t.Parallel()
+	for name, tcase := range evaluateTests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for i, expTest := range tcase.expressions {
+				expTest := expTest
+				t.Run(fmt.Sprintf("#%d - %s", i, expTest.expression), func(t *testing.T) {
+					t.Parallel()
+
+					eval, err := CreateEvaluator(expTest.expression, WithHookFn(expTest.hook))
+					require.NoError(t, err)
+
+					expected, expectedErr := evaluate(eval.ast, tcase.value, WithHookFn(expTest.hook))
+					match, err := eval.Evaluate(tcase.value)
+					require.Equal(t, expected, match)
+					if expectedErr != nil {
+						require.EqualError(t, err, expectedErr.Error())
+					} else {
+						require.NoError(t, err)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		expression string
+		datum      interface{}
+		err        string
+	}
+
+	tests := map[string]testCase{
+		"not convertible to bytes": {
+			expression: `Port matches "8"`,
+			datum:      map[string]int{"Port": 80},
+			err:        "value of type int is not convertible to []byte",
+		},
+		"invalid coercion": {
+			expression: `Port == "eighty"`,
+			datum:      map[string]int{"Port": 80},
+			err:        `error getting match value in expression: strconv.ParseInt: parsing "eighty": invalid syntax`,
+		},
+		"index and value": {
+			expression: `any Items as i, i { i == 1 }`,
+			datum:      map[string][]int{"Items": {1}},
+			err:        `"i" cannot be used as a placeholder for both the index and the value`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			eval, err := CreateEvaluator(tcase.expression)
+			require.NoError(t, err)
+
+			_, err = eval.Evaluate(tcase.datum)
+			require.EqualError(t, err, tcase.err)
+		})
+	}
+}
+
+// BenchmarkEvaluate_Interpreter runs the BenchmarkEvaluate cases with the
+// interpreter to compare it with the compiled expressions
+func BenchmarkEvaluate_Interpreter(b *testing.B) {
+	for name, tcase := range evaluateTests {
+		name := name
+		tcase := tcase
+		b.Run(name, func(b *testing.B) {
+			for i, expTest := range tcase.expressions {
+				expTest := expTest
+				b.Run(fmt.Sprintf("#%d", i), func(b *testing.B) {
+					if !expTest.benchQuick && !FullBenchmarks() {
+						b.Skip("Skipping benchmark - rerun with -bench-full to enable")
+					}
+
+					expr, err := CreateEvaluator(expTest.expression, WithHookFn(expTest.hook))
+					require.NoError(b, err)
+
+					b.ResetTimer()
+					for n := 0; n < b.N; n++ {
+						_, err = evaluate(expr.ast, tcase.value, WithHookFn(expTest.hook))
+						if expTest.err != "" {
+							require.Error(b, err)
+						} else {
+							require.NoError(b, err)
+						}
+					}
+				})
+			}
+		})
+	}
+}
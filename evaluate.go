@@ -4,13 +4,10 @@
 package bexpr
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
-	"regexp"
-	"strconv"
-	"strings"
 
 	"github.com/hashicorp/go-bexpr/grammar"
 	"github.com/mitchellh/pointerstructure"
@@ -69,122 +66,6 @@ func derefType(rtype reflect.Type) reflect.Type {
 	return rtype
 }
 
-func doMatchMatches(expression *grammar.MatchExpression, value reflect.Value) (bool, error) {
-	if !value.Type().ConvertibleTo(byteSliceTyp) {
-		return false, fmt.Errorf("value of type %s is not convertible to []byte", value.Type())
-	}
-
-	var re *regexp.Regexp
-	var ok bool
-	if expression.Value.Converted != nil {
-		re, ok = expression.Value.Converted.(*regexp.Regexp)
-	}
-	if !ok || re == nil {
-		var err error
-		re, err = regexp.Compile(expression.Value.Raw)
-		if err != nil {
-			return false, fmt.Errorf("failed to compile regular expression %q: %v", expression.Value.Raw, err)
-		}
-		expression.Value.Converted = re
-	}
-
-	return re.Match(value.Convert(byteSliceTyp).Interface().([]byte)), nil
-}
-
-func doMatchEqual(expression *grammar.MatchExpression, value reflect.Value) (bool, error) {
-	// NOTE: see preconditions in evaluategrammar.MatchExpressionRecurse
-	eqFn := primitiveEqualityFn(value.Kind())
-	if eqFn == nil {
-		return false, errors.New("unable to find suitable primitive comparison function for matching")
-	}
-	matchValue, err := getMatchExprValue(expression, value.Kind())
-	if err != nil {
-		return false, fmt.Errorf("error getting match value in expression: %w", err)
-	}
-	return eqFn(matchValue, value), nil
-}
-
-func doMatchIn(expression *grammar.MatchExpression, value reflect.Value) (bool, error) {
-	matchValue, err := getMatchExprValue(expression, value.Kind())
-	if err != nil {
-		return false, fmt.Errorf("error getting match value in expression: %w", err)
-	}
-
-	switch kind := value.Kind(); kind {
-	case reflect.Map:
-		found := value.MapIndex(reflect.ValueOf(matchValue))
-		return found.IsValid(), nil
-
-	case reflect.Slice, reflect.Array:
-		itemType := derefType(value.Type().Elem())
-		kind := itemType.Kind()
-		switch kind {
-		case reflect.Interface:
-			// If it's an interface, that is, the type was []interface{}, we
-			// have to treat each element individually, checking each element's
-			// type/kind and rederiving the match value.
-			for i := 0; i < value.Len(); i++ {
-				item := value.Index(i).Elem()
-				itemType := derefType(item.Type())
-				kind := itemType.Kind()
-				// We need to special case errors here. The reason is that in an
-				// interface slice there can be a mix/match of types, but the
-				// coerce functions expect a certain type. So the expression
-				// passed in might be `"true" in "/my/slice"` but the value it's
-				// checking against might be an integer, thus it will try to
-				// coerce "true" to an integer and fail. However, all of the
-				// functions use strconv which has a specific error type for
-				// syntax errors, so as a special case in this situation, don't
-				// error on a strconv.ErrSyntax, just continue on to the next
-				// element.
-				matchValue, err = getMatchExprValue(expression, kind)
-				if err != nil {
-					if errors.Is(err, strconv.ErrSyntax) {
-						continue
-					}
-					return false, errors.New(`error getting interface slice match value in expression`)
-				}
-				eqFn := primitiveEqualityFn(kind)
-				if eqFn == nil {
-					return false, fmt.Errorf(`unable to find suitable primitive comparison function for "in" comparison in interface slice: %s`, kind)
-				}
-				// the value will be the correct type as we verified the itemType
-				if eqFn(matchValue, reflect.Indirect(item)) {
-					return true, nil
-				}
-			}
-			return false, nil
-
-		default:
-			// Otherwise it's a concrete type and we can essentially cache the
-			// answers. First we need to re-derive the match value for equality
-			// assertion.
-			matchValue, err = getMatchExprValue(expression, kind)
-			if err != nil {
-				return false, fmt.Errorf("error getting match value in expression: %w", err)
-			}
-			eqFn := primitiveEqualityFn(kind)
-			if eqFn == nil {
-				return false, errors.New(`unable to find suitable primitive comparison function for "in" comparison`)
-			}
-			for i := 0; i < value.Len(); i++ {
-				item := value.Index(i)
-				// the value will be the correct type as we verified the itemType
-				if eqFn(matchValue, reflect.Indirect(item)) {
-					return true, nil
-				}
-			}
-			return false, nil
-		}
-
-	case reflect.String:
-		return strings.Contains(value.String(), matchValue.(string)), nil
-
-	default:
-		return false, fmt.Errorf("cannot perform in/contains operations on type %s for selector: %q", kind, expression.Selector)
-	}
-}
-
 func doMatchIsEmpty(matcher *grammar.MatchExpression, value reflect.Value) (bool, error) {
 	switch kind := value.Kind(); kind {
 	case reflect.Map, reflect.Slice, reflect.Array, reflect.Chan, reflect.String:
@@ -217,32 +98,6 @@ func doMatchIsNil(matcher *grammar.MatchExpression, value reflect.Value) (bool,
 	}
 }
 
-func getMatchExprValue(expression *grammar.MatchExpression, rvalue reflect.Kind) (interface{}, error) {
-	if expression.Value == nil {
-		return nil, nil
-	}
-
-	switch rvalue {
-	case reflect.Bool:
-		return CoerceBool(expression.Value.Raw)
-
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return CoerceInt64(expression.Value.Raw)
-
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return CoerceUint64(expression.Value.Raw)
-
-	case reflect.Float32:
-		return CoerceFloat32(expression.Value.Raw)
-
-	case reflect.Float64:
-		return CoerceFloat64(expression.Value.Raw)
-
-	default:
-		return expression.Value.Raw, nil
-	}
-}
-
 // evaluateNotPresent is called after a pointerstructure.ErrNotFound is
 // encountered during evaluation.
 //
@@ -251,9 +106,12 @@ func getMatchExprValue(expression *grammar.MatchExpression, rvalue reflect.Kind)
 //
 // Returns false if the Selector Path has a length of 1, or if the parent of
 // the Selector's Path is not a map, a pointerstructure.ErrNotFound error is
-// returned.
-func evaluateNotPresent(ptr pointerstructure.Pointer, datum interface{}) bool {
-	if len(ptr.Parts) < 2 {
+// returned. When datum is one of the roots of EvaluateRoots, rooted is set
+// and the Selector Path is relative to the root so it can have a length of 1.
+// It is also set by EvaluateThreeValued, for which the keys missing from the
+// datum itself are unknown.
+func evaluateNotPresent(ptr pointerstructure.Pointer, datum interface{}, rooted bool) bool {
+	if len(ptr.Parts) == 0 || (len(ptr.Parts) == 1 && !rooted) {
 		return false
 	}
 
@@ -264,7 +122,7 @@ func evaluateNotPresent(ptr pointerstructure.Pointer, datum interface{}) bool {
 	return reflect.ValueOf(val).Kind() == reflect.Map
 }
 
-// getValue resolves path to the value it references by first looking into the
+// lookupValue resolves path to the value it references by first looking into the
 // the local variables, then into the global datum state if it does not.
 //
 // When the path points to a local variable we have multiple cases we have to
@@ -281,8 +139,8 @@ func evaluateNotPresent(ptr pointerstructure.Pointer, datum interface{}) bool {
 //
 // `key` has no equivalent JSON Pointer. In that case we kept track of the the
 // concrete value instead of the path and we return it directly.
-func getValue(datum interface{}, path []string, opt ...Option) (interface{}, bool, error) {
-	opts := getOpts(opt...)
+func lookupValue(datum interface{}, path []string, opts *options) (interface{}, bool, error) {
+	local := false
 	if len(path) != 0 && len(opts.withLocalVariables) > 0 {
 		for i := len(opts.withLocalVariables) - 1; i >= 0; i-- {
 			name := path[0]
@@ -303,11 +161,20 @@ func getValue(datum interface{}, path []string, opt ...Option) (interface{}, boo
 					// path of the selector it replaces and continue searching
 					prefix := append([]string(nil), lv.path...)
 					path = append(prefix, path[1:]...)
+					local = true
 				}
 			}
 		}
 	}
 
+	// Paths reached through local variables could not be fully checked when
+	// the evaluator was created
+	if local && opts.withSelectorPolicy != nil {
+		if err := opts.withSelectorPolicy.check(path, false); err != nil {
+			return nil, false, err
+		}
+	}
+
 	// This is not a local variable, we use pointerstructure to look for it
 	// in the global datum
 	ptr := pointerstructure.Pointer{
@@ -317,6 +184,31 @@ func getValue(datum interface{}, path []string, opt ...Option) (interface{}, boo
 			ValueTransformationHook: opts.withHookFn,
 		},
 	}
+
+	// With EvaluateRoots the first part of the path picks the datum
+	rooted := opts.withRootValues != nil
+	if rooted {
+		var root interface{}
+		ok := len(path) > 0
+		if ok {
+			root, ok = opts.withRootValues[path[0]]
+		}
+		if !ok {
+			return nil, false, fmt.Errorf("error finding value in datum: %s does not reference a root", ptr.String())
+		}
+		for _, config := range opts.withRoots {
+			if config.name != path[0] {
+				continue
+			}
+			if config.tagName != "" {
+				ptr.Config.TagName = config.tagName
+			}
+			if config.hookFn != nil {
+				ptr.Config.ValueTransformationHook = config.hookFn
+			}
+		}
+		datum, ptr.Parts = root, path[1:]
+	}
 	val, err := ptr.Get(datum)
 	if err != nil {
 		if errors.Is(err, pointerstructure.ErrNotFound) {
@@ -326,12 +218,15 @@ func getValue(datum interface{}, path []string, opt ...Option) (interface{}, boo
 			case opts.withUnknown != nil:
 				err = nil
 				val = *opts.withUnknown
-			case evaluateNotPresent(ptr, datum):
+			case evaluateNotPresent(ptr, datum, rooted || opts.withMissingTopKeys):
 				return nil, false, nil
 			}
 		}
 
 		if err != nil {
+			if rooted {
+				return false, false, fmt.Errorf("error finding value in root %q: %w", path[0], err)
+			}
 			return false, false, fmt.Errorf("error finding value in datum: %w", err)
 		}
 	}
@@ -339,185 +234,46 @@ func getValue(datum interface{}, path []string, opt ...Option) (interface{}, boo
 	return val, true, nil
 }
 
-func evaluateMatchExpression(expression *grammar.MatchExpression, datum interface{}, opt ...Option) (bool, error) {
-	val, present, err := getValue(
-		datum,
-		expression.Selector.Path,
-		opt...,
-	)
-	if err != nil {
-		return false, err
-	}
-	if !present {
-		return expression.Operator.NotPresentDisposition(), nil
-	}
+// interruptInterval is the number of iterations between two checks of the
+// context of an evaluation
+const interruptInterval = 1024
 
-	if jn, ok := val.(json.Number); ok {
-		if jni, err := jn.Int64(); err == nil {
-			val = jni
-		} else if jnf, err := jn.Float64(); err == nil {
-			val = jnf
-		} else {
-			return false, fmt.Errorf("unable to convert json number %s to int or float", jn)
-		}
-	}
+// interrupt tracks the context of an evaluation. It is shared by the
+// iterations of every collection expression of an evaluation so the context is
+// not checked too often when they are nested.
+type interrupt struct {
+	ctx   context.Context
+	count int
+}
 
-	rvalue := reflect.Indirect(reflect.ValueOf(val))
-	switch expression.Operator {
-	case grammar.MatchEqual:
-		return doMatchEqual(expression, rvalue)
-	case grammar.MatchNotEqual:
-		result, err := doMatchEqual(expression, rvalue)
-		if err == nil {
-			return !result, nil
-		}
-		return false, err
-	case grammar.MatchIn:
-		return doMatchIn(expression, rvalue)
-	case grammar.MatchNotIn:
-		result, err := doMatchIn(expression, rvalue)
-		if err == nil {
-			return !result, nil
-		}
-		return false, err
-	case grammar.MatchIsEmpty:
-		return doMatchIsEmpty(expression, rvalue)
-	case grammar.MatchIsNotEmpty:
-		result, err := doMatchIsEmpty(expression, rvalue)
-		if err == nil {
-			return !result, nil
-		}
-		return false, err
-	case grammar.MatchMatches:
-		return doMatchMatches(expression, rvalue)
-	case grammar.MatchNotMatches:
-		result, err := doMatchMatches(expression, rvalue)
-		if err == nil {
-			return !result, nil
-		}
-		return false, err
-	case grammar.MatchIsNil:
-		return doMatchIsNil(expression, rvalue)
-	case grammar.MatchIsNotNil:
-		result, err := doMatchIsNil(expression, rvalue)
-		if err == nil {
-			return !result, nil
-		}
-		return false, err
-	default:
-		return false, fmt.Errorf("invalid match operation: %d", expression.Operator)
+// newInterrupt returns nil when ctx can never be done, which disables the
+// checks
+func newInterrupt(ctx context.Context) *interrupt {
+	if ctx.Done() == nil {
+		return nil
 	}
+	return &interrupt{ctx: ctx}
 }
 
-func evaluateCollectionExpression(expression *grammar.CollectionExpression, datum interface{}, opt ...Option) (bool, error) {
-	val, present, err := getValue(
-		datum,
-		expression.Selector.Path,
-		opt...,
-	)
-	if err != nil {
-		return false, err
+// err returns an error when the context is done
+func (in *interrupt) err() error {
+	if in == nil {
+		return nil
 	}
-	if !present {
-		return expression.Op == grammar.CollectionOpAll, nil
-	}
-
-	v := reflect.ValueOf(val)
-
-	var keys []reflect.Value
-	if v.Kind() == reflect.Map {
-		if v.Type().Key() != reflect.TypeOf("") {
-			return false, fmt.Errorf("%s can only iterate over maps indexed with strings", expression.Op)
-		}
-		keys = v.MapKeys()
-	}
-
-	switch v.Kind() {
-	case reflect.Slice, reflect.Array, reflect.Map:
-		for i := 0; i < v.Len(); i++ {
-			innerOpt := append([]Option(nil), opt...)
-
-			if expression.NameBinding.Mode == grammar.CollectionBindIndexAndValue &&
-				expression.NameBinding.Index == expression.NameBinding.Value {
-				return false, fmt.Errorf("%q cannot be used as a placeholder for both the index and the value", expression.NameBinding.Index)
-			}
-
-			if v.Kind() == reflect.Map {
-				key := keys[i]
-				if expression.NameBinding.Default != "" {
-					innerOpt = append(innerOpt, WithLocalVariable(expression.NameBinding.Default, nil, key.Interface()))
-				}
-				if expression.NameBinding.Index != "" {
-					innerOpt = append(innerOpt, WithLocalVariable(expression.NameBinding.Index, nil, key.Interface()))
-				}
-				if expression.NameBinding.Value != "" {
-					path := make([]string, 0, len(expression.Selector.Path)+1)
-					path = append(path, expression.Selector.Path...)
-					path = append(path, key.Interface().(string))
-					innerOpt = append(innerOpt, WithLocalVariable(expression.NameBinding.Value, path, nil))
-				}
-			} else {
-				if expression.NameBinding.Index != "" {
-					innerOpt = append(innerOpt, WithLocalVariable(expression.NameBinding.Index, nil, i))
-				}
-
-				pathValue := make([]string, 0, len(expression.Selector.Path)+1)
-				pathValue = append(pathValue, expression.Selector.Path...)
-				pathValue = append(pathValue, fmt.Sprintf("%d", i))
-				if expression.NameBinding.Default != "" {
-					innerOpt = append(innerOpt, WithLocalVariable(expression.NameBinding.Default, pathValue, nil))
-				}
-				if expression.NameBinding.Value != "" {
-					innerOpt = append(innerOpt, WithLocalVariable(expression.NameBinding.Value, pathValue, nil))
-				}
-			}
-
-			result, err := evaluate(expression.Inner, datum, innerOpt...)
-			if err != nil {
-				return false, err
-			}
-			if (result && expression.Op == grammar.CollectionOpAny) || (!result && expression.Op == grammar.CollectionOpAll) {
-				return result, nil
-			}
-		}
-
-		return expression.Op == grammar.CollectionOpAll, nil
-
-	default:
-		return false, fmt.Errorf(`%s is not a list or a map`, expression.Selector.String())
+	if err := in.ctx.Err(); err != nil {
+		return fmt.Errorf("evaluation interrupted: %w", err)
 	}
+	return nil
 }
 
-func evaluate(ast grammar.Expression, datum interface{}, opt ...Option) (bool, error) {
-	switch node := ast.(type) {
-	case *grammar.UnaryExpression:
-		switch node.Operator {
-		case grammar.UnaryOpNot:
-			result, err := evaluate(node.Operand, datum, opt...)
-			return !result, err
-		}
-	case *grammar.BinaryExpression:
-		switch node.Operator {
-		case grammar.BinaryOpAnd:
-			result, err := evaluate(node.Left, datum, opt...)
-			if err != nil || !result {
-				return result, err
-			}
-
-			return evaluate(node.Right, datum, opt...)
-
-		case grammar.BinaryOpOr:
-			result, err := evaluate(node.Left, datum, opt...)
-			if err != nil || result {
-				return result, err
-			}
-
-			return evaluate(node.Right, datum, opt...)
-		}
-	case *grammar.MatchExpression:
-		return evaluateMatchExpression(node, datum, opt...)
-	case *grammar.CollectionExpression:
-		return evaluateCollectionExpression(node, datum, opt...)
+// check is called on each iteration and checks the context periodically
+func (in *interrupt) check() error {
+	if in == nil {
+		return nil
+	}
+	in.count++
+	if in.count%interruptInterval != 0 {
+		return nil
 	}
-	return false, fmt.Errorf("invalid AST node")
+	return in.err()
 }
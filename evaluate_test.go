@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/hashicorp/go-bexpr/grammar"
 	"github.com/mitchellh/pointerstructure"
 	"github.com/stretchr/testify/require"
 )
@@ -401,6 +402,77 @@ func TestEvaluate(t *testing.T) {
 	}
 }
 
+func TestEvaluate_Simplified(t *testing.T) {
+	t.Parallel()
+	for name, tcase := range evaluateTests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for i, expTest := range tcase.expressions {
+				if expTest.err != "" {
+					continue
+				}
+				t.Run(fmt.Sprintf("#%d - %s", i, expTest.expression), func(t *testing.T) {
+					expr, err := CreateEvaluator(expTest.expression, WithHookFn(expTest.hook))
+					require.NoError(t, err)
+					simplified, err := CreateEvaluatorFromAST(grammar.Simplify(expr.ast), WithHookFn(expTest.hook))
+					require.NoError(t, err)
+
+					match, err := simplified.Evaluate(tcase.value)
+					require.NoError(t, err)
+					require.Equal(t, expTest.result, match)
+				})
+			}
+		})
+	}
+}
+
+func TestEvaluate_IsOneOf(t *testing.T) {
+	t.Parallel()
+
+	datum := map[string]interface{}{"M": map[string]interface{}{"Int": 2, "String": "b"}}
+	oneOf := func(op grammar.MatchOperator, field string, raws ...string) *grammar.MatchExpression {
+		expr := &grammar.MatchExpression{
+			Selector: grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: []string{"M", field}},
+			Operator: op,
+		}
+		for _, raw := range raws {
+			expr.Values = append(expr.Values, &grammar.MatchValue{Raw: raw})
+		}
+		return expr
+	}
+
+	cases := []struct {
+		expr   grammar.Expression
+		result bool
+		err    string
+	}{
+		{expr: oneOf(grammar.MatchIsOneOf, "Int", "1", "2"), result: true},
+		{expr: oneOf(grammar.MatchIsOneOf, "Int", "1", "3"), result: false},
+		{expr: oneOf(grammar.MatchIsNotOneOf, "Int", "1", "3"), result: true},
+		{expr: oneOf(grammar.MatchIsOneOf, "String", "a", "b"), result: true},
+		{expr: oneOf(grammar.MatchIsNotOneOf, "String", "a", "b"), result: false},
+		{expr: oneOf(grammar.MatchIsOneOf, "Missing", "a", "b"), result: false},
+		{expr: oneOf(grammar.MatchIsNotOneOf, "Missing", "a", "b"), result: true},
+		{expr: oneOf(grammar.MatchIsOneOf, "Int", "a"), err: "error getting match value in expression"},
+		{expr: &grammar.ConstantExpression{Value: true}, result: true},
+	}
+
+	for _, tc := range cases {
+		eval, err := CreateEvaluatorFromAST(tc.expr)
+		require.NoError(t, err)
+		match, err := eval.Evaluate(datum)
+		if tc.err != "" {
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.result, match)
+	}
+}
+
 func TestWithHookFn(t *testing.T) {
 	t.Parallel()
 	type testStruct struct {
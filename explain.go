@@ -0,0 +1,175 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// Explanation describes how a node of an expression was evaluated, see
+// Evaluator.Explain. Its String method renders the tree for humans.
+type Explanation struct {
+	// Expression is the node of the syntax tree explained
+	Expression grammar.Expression
+	// Result is the result of the node, false when Err is set or when the
+	// node was skipped
+	Result bool
+	// Err is the error the evaluation of the node failed with
+	Err error
+	// Skipped is true for the operands of and and or expressions that were
+	// not evaluated because the result was already known
+	Skipped bool
+
+	// Value is the value found for the selector of a match expression, as it
+	// was matched: pointers are dereferenced and JSON numbers converted
+	Value interface{}
+	// Missing is true when the selector of a match or collection expression
+	// references a missing map key, Result then being given by the
+	// NotPresentDisposition of its operator
+	Missing bool
+	// Decider is the index, or the key for maps, of the element that decided
+	// the result of a collection expression. It is nil when every element was
+	// evaluated.
+	Decider interface{}
+
+	// Children explains the operands of not, and and or expressions, and
+	// the body of collection expressions for the element that decided
+	// their result
+	Children []*Explanation
+}
+
+// Explain evaluates the expression against datum like Evaluate and returns
+// how each node was evaluated, along with the error of the evaluation.
+func (eval *Evaluator) Explain(datum interface{}) (*Explanation, error) {
+	explanation := explain(eval.root, datum, eval.runOptions(nil))
+	return explanation, explanation.Err
+}
+
+func explain(n *compiledNode, datum interface{}, opts *options) *Explanation {
+	e := &Explanation{Expression: n.expression}
+	if e.Err = opts.withBudget.visit(); e.Err != nil {
+		return e
+	}
+
+	switch node := n.expression.(type) {
+	case *grammar.UnaryExpression:
+		operand := explain(n.children[0], datum, opts)
+		e.Children = []*Explanation{operand}
+		e.Result, e.Err = !operand.Result, operand.Err
+	case *grammar.BinaryExpression:
+		left := explain(n.children[0], datum, opts)
+		e.Children = []*Explanation{left}
+		if left.Err != nil || left.Result == (node.Operator == grammar.BinaryOpOr) {
+			e.Result, e.Err = left.Result, left.Err
+			e.Children = append(e.Children, &Explanation{Expression: node.Right, Skipped: true})
+			break
+		}
+		right := explain(n.children[1], datum, opts)
+		e.Children = append(e.Children, right)
+		e.Result, e.Err = right.Result, right.Err
+	case *grammar.MatchExpression:
+		value, present, err := n.match.lookup(datum, opts)
+		switch {
+		case err != nil:
+			e.Err = err
+		case !present:
+			e.Missing, e.Result = true, node.Operator.NotPresentDisposition()
+		default:
+			if value.IsValid() && value.CanInterface() {
+				e.Value = value.Interface()
+			}
+			e.Result, e.Err = n.match.matchValue(value, opts)
+		}
+	case *grammar.CollectionExpression:
+		explainCollection(e, node, n.children[0], datum, opts)
+	case *grammar.ConstantExpression:
+		e.Result = node.Value
+	}
+
+	if e.Err != nil {
+		e.Result = false
+	}
+	return e
+}
+
+func explainCollection(e *Explanation, node *grammar.CollectionExpression, body *compiledNode, datum interface{}, opts *options) {
+	isAll := node.Op == grammar.CollectionOpAll
+	e.Result = isAll
+	present, err := iterateCollection(node, datum, opts, func(innerOpts *options, keys []reflect.Value, i int) (bool, error) {
+		inner := explain(body, datum, innerOpts)
+		if inner.Err == nil && inner.Result == isAll {
+			return false, nil
+		}
+		if keys != nil {
+			e.Decider = keys[i].Interface()
+		} else {
+			e.Decider = i
+		}
+		e.Children = []*Explanation{inner}
+		e.Result = inner.Result
+		return true, inner.Err
+	})
+	e.Err = err
+	e.Missing = !present && err == nil
+}
+
+// String renders the explanation as an indented tree with a line per node
+func (e *Explanation) String() string {
+	var b strings.Builder
+	e.render(&b, 0)
+	return b.String()
+}
+
+func (e *Explanation) render(b *strings.Builder, level int) {
+	b.WriteString(strings.Repeat("  ", level))
+	switch {
+	case e.Skipped:
+		b.WriteString("skipped")
+	case e.Err != nil:
+		b.WriteString("error")
+	default:
+		fmt.Fprintf(b, "%t", e.Result)
+	}
+	b.WriteString(": ")
+
+	switch node := e.Expression.(type) {
+	case *grammar.UnaryExpression:
+		b.WriteString("not")
+	case *grammar.BinaryExpression:
+		b.WriteString(strings.ToLower(node.Operator.String()))
+	case *grammar.ConstantExpression:
+		fmt.Fprintf(b, "%t", node.Value)
+	default:
+		b.WriteString(grammar.Literal{Expression: node}.String())
+	}
+
+	switch {
+	case e.Skipped:
+	case e.Err != nil:
+		fmt.Fprintf(b, " (%v)", e.Err)
+	case e.Missing:
+		b.WriteString(" (missing)")
+	case e.Value != nil:
+		if s, ok := e.Value.(string); ok {
+			fmt.Fprintf(b, " (value %q)", s)
+		} else {
+			fmt.Fprintf(b, " (value %v)", e.Value)
+		}
+	case e.Decider != nil:
+		if s, ok := e.Decider.(string); ok {
+			fmt.Fprintf(b, " (decided by %q)", s)
+		} else {
+			fmt.Fprintf(b, " (decided by %v)", e.Decider)
+		}
+	}
+	b.WriteString("\n")
+
+	for _, child := range e.Children {
+		child.render(b, level+1)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExplain_Evaluate checks explanations have the result of Evaluate
+func TestExplain_Evaluate(t *testing.T) {
+	t.Parallel()
+	for name, tcase := range evaluateTests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for i, expTest := range tcase.expressions {
+				eval, err := CreateEvaluator(expTest.expression, WithHookFn(expTest.hook))
+				require.NoError(t, err)
+
+				match, expectedErr := eval.Evaluate(tcase.value)
+				explanation, err := eval.Explain(tcase.value)
+				require.Equal(t, expectedErr, err, "#%d - %s", i, expTest.expression)
+				require.Equal(t, expectedErr, explanation.Err)
+				if expectedErr == nil {
+					require.Equal(t, match, explanation.Result, "#%d - %s", i, expTest.expression)
+				}
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		expression string
+		rendered   string
+		err        string
+	}
+
+	datum := map[string]interface{}{
+		"Name":   "web",
+		"Port":   8080,
+		"Labels": map[string]string{"env": "prod"},
+		"Tags":   []string{"a", "b"},
+		"Items":  map[string]map[string]int{"x": {"Size": 1}},
+	}
+
+	tests := map[string]testCase{
+		"short-circuit": {
+			expression: `Name == "db" and Port == 80`,
+			rendered: `false: and
+  false: Name == "db" (value "web")
+  skipped: Port == "80"
+`,
+		},
+		"or": {
+			expression: `Name == "db" or not Port == 80`,
+			rendered: `true: or
+  false: Name == "db" (value "web")
+  true: not
+    false: Port == "80" (value 8080)
+`,
+		},
+		"missing key": {
+			expression: `Labels.team != "core"`,
+			rendered: `true: Labels.team != "core" (missing)
+`,
+		},
+		"any decided": {
+			expression: `any Tags as i, tag { tag == "b" }`,
+			rendered: `true: any Tags as i, tag { ... } (decided by 1)
+  true: tag == "b" (value "b")
+`,
+		},
+		"all undecided": {
+			expression: `all Tags as tag { tag != "c" }`,
+			rendered: `true: all Tags as tag { ... }
+`,
+		},
+		"all decided by map key": {
+			expression: `all Items as k, v { v.Size == 2 }`,
+			rendered: `false: all Items as k, v { ... } (decided by "x")
+  false: v.Size == "2" (value 1)
+`,
+		},
+		"error": {
+			expression: `Name == "web" and Port == "http"`,
+			rendered: `error: and (error getting match value in expression: strconv.ParseInt: parsing "http": invalid syntax)
+  true: Name == "web" (value "web")
+  error: Port == "http" (error getting match value in expression: strconv.ParseInt: parsing "http": invalid syntax)
+`,
+			err: `error getting match value in expression: strconv.ParseInt: parsing "http": invalid syntax`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			eval, err := CreateEvaluator(tcase.expression)
+			require.NoError(t, err)
+
+			explanation, err := eval.Explain(datum)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tcase.rendered, explanation.String())
+		})
+	}
+}
+
+func TestExplain_Tree(t *testing.T) {
+	t.Parallel()
+
+	eval, err := CreateEvaluator(`Labels.team == "core" or (any Tags as tag { tag == "b" })`)
+	require.NoError(t, err)
+
+	explanation, err := eval.Explain(map[string]interface{}{
+		"Labels": map[string]string{},
+		"Tags":   []string{"a", "b"},
+	})
+	require.NoError(t, err)
+	require.True(t, explanation.Result)
+	require.Len(t, explanation.Children, 2)
+
+	missing := explanation.Children[0]
+	require.True(t, missing.Missing)
+	require.False(t, missing.Result)
+	require.Nil(t, missing.Value)
+
+	collection := explanation.Children[1]
+	require.IsType(t, &grammar.CollectionExpression{}, collection.Expression)
+	require.Equal(t, 1, collection.Decider)
+	require.Len(t, collection.Children, 1)
+	require.Equal(t, "b", collection.Children[0].Value)
+	require.Equal(t, fmt.Sprint(explanation), explanation.String())
+}
@@ -4,13 +4,21 @@
 package bexpr
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+
+	"github.com/hashicorp/go-bexpr/grammar"
 )
 
+// Filter selects the elements of maps, slices and arrays matching an
+// expression. Like Evaluator, it is safe for concurrent use.
 type Filter struct {
 	// The underlying boolean expression evaluator
 	evaluator *Evaluator
+	// keyVariable is the local variable holding the key or index of the
+	// evaluated elements, see WithKeyVariable
+	keyVariable string
 }
 
 // Creates a filter to operate on the given data type.
@@ -18,27 +26,55 @@ type Filter struct {
 // For example, if you want to filter a []Foo then the data type to pass here is either []Foo or just Foo.
 // If no expression is provided the nil filter will be returned but is not an error. This is done
 // to allow for executing the nil filter which is just a no-op
-func CreateFilter(expression string) (*Filter, error) {
+// The options are passed to CreateEvaluator and support the same Option types,
+// along with WithKeyVariable.
+func CreateFilter(expression string, opts ...Option) (*Filter, error) {
 	if expression == "" {
 		// nil filter
 		return nil, nil
 	}
-	exp, err := CreateEvaluator(expression)
+	exp, err := CreateEvaluator(expression, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create boolean expression evaluator: %v", err)
+	}
+
+	return &Filter{
+		evaluator:   exp,
+		keyVariable: exp.createOpts.withKeyVariable,
+	}, nil
+}
+
+// CreateFilterFromAST creates a filter using an expression that was not parsed
+// from the bexpr syntax, see CreateEvaluatorFromAST.
+func CreateFilterFromAST(ast grammar.Expression, opts ...Option) (*Filter, error) {
+	exp, err := CreateEvaluatorFromAST(ast, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create boolean expression evaluator: %v", err)
 	}
 
 	return &Filter{
-		evaluator: exp,
+		evaluator:   exp,
+		keyVariable: exp.createOpts.withKeyVariable,
 	}, nil
 }
 
 // Execute the filter. If called on a nil filter this is a no-op and
 // will return the original data
 func (f *Filter) Execute(data interface{}) (interface{}, error) {
+	return f.ExecuteContext(context.Background(), data)
+}
+
+// ExecuteContext is like Execute but stops once ctx is done, returning an
+// error wrapping ctx.Err(). The context is checked periodically between the
+// elements of data and while evaluating them.
+func (f *Filter) ExecuteContext(ctx context.Context, data interface{}) (interface{}, error) {
 	if f == nil {
 		return data, nil
 	}
+	in := newInterrupt(ctx)
+	if err := in.err(); err != nil {
+		return nil, err
+	}
 
 	rvalue := reflect.ValueOf(data)
 	rtype := rvalue.Type()
@@ -52,11 +88,14 @@ func (f *Filter) Execute(data interface{}) (interface{}, error) {
 		newSlice := reflect.MakeSlice(rtype, 0, rvalue.Len())
 
 		for i := 0; i < rvalue.Len(); i++ {
+			if err := in.check(); err != nil {
+				return nil, err
+			}
 			item := rvalue.Index(i)
 			if !item.CanInterface() {
 				return nil, fmt.Errorf("Slice/Array value can not be used")
 			}
-			result, err := f.evaluator.Evaluate(item.Interface())
+			result, err := f.evaluate(item.Interface(), i, in)
 			if err != nil {
 				return nil, err
 			}
@@ -73,13 +112,16 @@ func (f *Filter) Execute(data interface{}) (interface{}, error) {
 		// TODO (mkeeler) - Update to use a MapRange iterator once Go 1.12 is usable
 		// for all of our products
 		for _, mapKey := range rvalue.MapKeys() {
+			if err := in.check(); err != nil {
+				return nil, err
+			}
 			item := rvalue.MapIndex(mapKey)
 
 			if !item.CanInterface() {
 				return nil, fmt.Errorf("map value cannot be used")
 			}
 
-			result, err := f.evaluator.Evaluate(item.Interface())
+			result, err := f.evaluate(item.Interface(), mapKey.Interface(), in)
 			if err != nil {
 				return nil, err
 			}
@@ -94,3 +136,16 @@ func (f *Filter) Execute(data interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("only slices, arrays and maps are filterable")
 	}
 }
+
+// evaluate evaluates an element of the filtered data, key being its key or
+// index
+func (f *Filter) evaluate(item, key interface{}, in *interrupt) (bool, error) {
+	opts := f.evaluator.runOptions(in)
+	if f.keyVariable != "" {
+		keyOpts := *opts
+		locals := opts.withLocalVariables[:len(opts.withLocalVariables):len(opts.withLocalVariables)]
+		keyOpts.withLocalVariables = append(locals, localVariable{name: f.keyVariable, value: key})
+		opts = &keyOpts
+	}
+	return f.evaluator.root.program(item, opts)
+}
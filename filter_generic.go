@@ -0,0 +1,79 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"iter"
+)
+
+// FilterSlice returns the elements of data matching the filter, like Execute
+// but without going through reflection. The nil filter returns data as is.
+func FilterSlice[T any](f *Filter, data []T) ([]T, error) {
+	if f == nil {
+		return data, nil
+	}
+
+	results := make([]T, 0, len(data))
+	for i, item := range data {
+		result, err := f.evaluate(item, i, nil)
+		if err != nil {
+			return nil, err
+		}
+		if result {
+			results = append(results, item)
+		}
+	}
+	return results, nil
+}
+
+// FilterMap returns the entries of data whose value matches the filter, like
+// Execute but without going through reflection. The nil filter returns data
+// as is.
+func FilterMap[K comparable, V any](f *Filter, data map[K]V) (map[K]V, error) {
+	if f == nil {
+		return data, nil
+	}
+
+	results := make(map[K]V)
+	for key, item := range data {
+		result, err := f.evaluate(item, key, nil)
+		if err != nil {
+			return nil, err
+		}
+		if result {
+			results[key] = item
+		}
+	}
+	return results, nil
+}
+
+// FilterSeq returns a sequence of the elements of seq matching the filter,
+// which are evaluated as the sequence is iterated. An evaluation error is
+// yielded with the zero value of T and ends the sequence. The nil filter
+// yields every element. The index of WithKeyVariable is the position of the
+// element in seq.
+func FilterSeq[T any](f *Filter, seq iter.Seq[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		i := 0
+		for item := range seq {
+			if f == nil {
+				if !yield(item, nil) {
+					return
+				}
+				continue
+			}
+
+			result, err := f.evaluate(item, i, nil)
+			i++
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if result && !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
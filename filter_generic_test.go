@@ -0,0 +1,112 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilterGeneric_Execute checks the generic helpers return what Execute
+// does
+func TestFilterGeneric_Execute(t *testing.T) {
+	t.Parallel()
+
+	for _, expression := range []string{"X==1", "Y==`c`", "X==4", `Y matches "^[ab]$"`} {
+		flt, err := CreateFilter(expression)
+		require.NoError(t, err)
+
+		expected, err := flt.Execute(testSlice)
+		require.NoError(t, err)
+		results, err := FilterSlice(flt, testSlice)
+		require.NoError(t, err)
+		require.Equal(t, expected, results, expression)
+
+		results, err = FilterSlice(flt, testArray[:])
+		require.NoError(t, err)
+		require.Equal(t, expected, results, expression)
+
+		var seqResults []testStruct
+		for item, err := range FilterSeq(flt, slices.Values(testSlice)) {
+			require.NoError(t, err)
+			seqResults = append(seqResults, item)
+		}
+		require.ElementsMatch(t, expected, seqResults, expression)
+
+		expected, err = flt.Execute(testMap)
+		require.NoError(t, err)
+		mapResults, err := FilterMap(flt, testMap)
+		require.NoError(t, err)
+		require.Equal(t, expected, mapResults, expression)
+	}
+}
+
+func TestFilterGeneric_NilFilter(t *testing.T) {
+	t.Parallel()
+
+	var flt *Filter
+	results, err := FilterSlice(flt, testSlice)
+	require.NoError(t, err)
+	require.Equal(t, testSlice, results)
+
+	mapResults, err := FilterMap(flt, testMap)
+	require.NoError(t, err)
+	require.Equal(t, testMap, mapResults)
+
+	var seqResults []testStruct
+	for item, err := range FilterSeq(flt, slices.Values(testSlice)) {
+		require.NoError(t, err)
+		seqResults = append(seqResults, item)
+	}
+	require.Equal(t, testSlice, seqResults)
+}
+
+func TestFilterGeneric_Errors(t *testing.T) {
+	t.Parallel()
+
+	flt, err := CreateFilter(`X == "a"`)
+	require.NoError(t, err)
+	expected := `error getting match value in expression: strconv.ParseInt: parsing "a": invalid syntax`
+
+	_, err = FilterSlice(flt, testSlice)
+	require.EqualError(t, err, expected)
+
+	_, err = FilterMap(flt, testMap)
+	require.EqualError(t, err, expected)
+
+	calls := 0
+	for item, err := range FilterSeq(flt, maps.Values(testMap)) {
+		calls++
+		require.Zero(t, item)
+		require.EqualError(t, err, expected)
+	}
+	require.Equal(t, 1, calls)
+}
+
+func TestFilterSeq_Lazy(t *testing.T) {
+	t.Parallel()
+
+	flt, err := CreateFilter(`X != 1`)
+	require.NoError(t, err)
+
+	// Elements are only evaluated as the sequence is iterated
+	pulled := 0
+	seq := func(yield func(testStruct) bool) {
+		for _, item := range testSlice {
+			pulled++
+			if !yield(item) {
+				return
+			}
+		}
+	}
+	for item, err := range FilterSeq(flt, seq) {
+		require.NoError(t, err)
+		require.Equal(t, testStruct{X: 2, Y: "a"}, item)
+		break
+	}
+	require.Equal(t, 3, pulled)
+}
@@ -4,8 +4,13 @@
 package bexpr
 
 import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/go-bexpr/grammar"
 	"github.com/stretchr/testify/require"
 )
 
@@ -157,6 +162,139 @@ func TestFilter(t *testing.T) {
 	}
 }
 
+func TestCreateFilterFromAST(t *testing.T) {
+	t.Parallel()
+
+	ast := &grammar.MatchExpression{
+		Selector: grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: []string{"Y"}},
+		Operator: grammar.MatchIsOneOf,
+		Values:   []*grammar.MatchValue{{Raw: "a"}, {Raw: "c"}},
+	}
+	flt, err := CreateFilterFromAST(ast)
+	require.NoError(t, err)
+
+	results, err := flt.Execute(testSlice)
+	require.NoError(t, err)
+	require.Equal(t, []testStruct{{X: 1, Y: "a"}, {X: 2, Y: "a"}, {X: 3, Y: "c"}}, results)
+
+	_, err = CreateFilterFromAST(nil)
+	require.EqualError(t, err, "failed to create boolean expression evaluator: missing expression")
+}
+
+func TestCreateFilter_Options(t *testing.T) {
+	t.Parallel()
+
+	type tagged struct {
+		Name  string `json:"name"`
+		Attrs map[string]string
+	}
+	data := []tagged{
+		{Name: "web", Attrs: map[string]string{"env": "prod"}},
+		{Name: "db", Attrs: map[string]string{}},
+	}
+
+	flt, err := CreateFilter(`name == "web"`, WithTagName("json"))
+	require.NoError(t, err)
+	results, err := flt.Execute(data)
+	require.NoError(t, err)
+	require.Equal(t, data[:1], results)
+
+	flt, err = CreateFilter(`Attrs.env == "dev"`, WithUnknownValue("dev"))
+	require.NoError(t, err)
+	results, err = flt.Execute(data)
+	require.NoError(t, err)
+	require.Equal(t, data[1:], results)
+
+	_, err = CreateFilter(`name == "a" or name == "b"`, WithMaxExpressions(1))
+	require.ErrorContains(t, err, "failed to create boolean expression evaluator")
+
+	// The empty expression is still the nil filter
+	flt, err = CreateFilter("", WithTagName("json"))
+	require.NoError(t, err)
+	require.Nil(t, flt)
+}
+
+func TestFilter_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	flt, err := CreateFilter(`Y matches "^[ab]$" and X != 1`)
+	require.NoError(t, err)
+
+	type run struct {
+		sliceResults interface{}
+		sliceErr     error
+		mapResults   interface{}
+		mapErr       error
+	}
+
+	// The results are checked once the goroutines are done since require
+	// must be called from the goroutine running the test
+	var runs [16][100]run
+	var wg sync.WaitGroup
+	for i := range runs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := range runs[i] {
+				r := &runs[i][j]
+				r.sliceResults, r.sliceErr = flt.Execute(testSlice)
+				r.mapResults, r.mapErr = flt.Execute(testMap)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range runs {
+		for _, r := range runs[i] {
+			require.NoError(t, r.sliceErr)
+			require.Equal(t, []testStruct{{X: 2, Y: "a"}, {X: 2, Y: "b"}}, r.sliceResults)
+			require.NoError(t, r.mapErr)
+			require.Len(t, r.mapResults, 2)
+		}
+	}
+}
+
+func TestFilter_ExecuteContext(t *testing.T) {
+	t.Parallel()
+
+	flt, err := CreateFilter(`X == 1`)
+	require.NoError(t, err)
+
+	results, err := flt.ExecuteContext(context.Background(), testSlice)
+	require.NoError(t, err)
+	require.Equal(t, []testStruct{{X: 1, Y: "a"}, {X: 1, Y: "b"}}, results)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = flt.ExecuteContext(ctx, testMap)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// The nil filter has nothing to interrupt
+	var nilFilter *Filter
+	results, err = nilFilter.ExecuteContext(ctx, testSlice)
+	require.NoError(t, err)
+	require.Equal(t, testSlice, results)
+
+	// Cancel between elements
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	evaluated := 0
+	large := make([]testStruct, 10000)
+	flt, err = CreateFilterFromAST(&grammar.MatchExpression{
+		Selector: grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: []string{"X"}},
+		Operator: grammar.MatchEqual,
+		Value:    &grammar.MatchValue{Raw: "0"},
+	}, WithHookFn(func(v reflect.Value) reflect.Value {
+		evaluated++
+		cancel()
+		return v
+	}))
+	require.NoError(t, err)
+	_, err = flt.ExecuteContext(ctx, large)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, evaluated, 5000)
+}
+
 func BenchmarkFilter(b *testing.B) {
 	type benchCase struct {
 		expression string
@@ -201,3 +339,45 @@ func BenchmarkFilter(b *testing.B) {
 		})
 	}
 }
+
+func TestFilter_KeyVariable(t *testing.T) {
+	t.Parallel()
+
+	flt, err := CreateFilter(`Key matches "^t" and X == 1`, WithKeyVariable("Key"), WithAllowedSelectors("/X"))
+	require.NoError(t, err)
+
+	results, err := flt.Execute(testMap)
+	require.NoError(t, err)
+	require.Equal(t, map[string]testStruct{"two": {X: 1, Y: "b"}}, results)
+
+	mapResults, err := FilterMap(flt, testMap)
+	require.NoError(t, err)
+	require.Equal(t, results, mapResults)
+
+	// Slices and arrays expose the index of their elements
+	flt, err = CreateFilter(`Y == "a" or Index == 4`, WithKeyVariable("Index"))
+	require.NoError(t, err)
+	expected := []testStruct{{X: 1, Y: "a"}, {X: 2, Y: "a"}, {X: 3, Y: "c"}}
+
+	results, err = flt.Execute(testArray)
+	require.NoError(t, err)
+	require.Equal(t, expected, results)
+
+	sliceResults, err := FilterSlice(flt, testSlice)
+	require.NoError(t, err)
+	require.Equal(t, expected, sliceResults)
+
+	var seqResults []testStruct
+	for item, err := range FilterSeq(flt, slices.Values(testSlice)) {
+		require.NoError(t, err)
+		seqResults = append(seqResults, item)
+	}
+	require.Equal(t, expected, seqResults)
+
+	// The variable shadows the fields of the elements
+	flt, err = CreateFilter(`X == 0`, WithKeyVariable("X"))
+	require.NoError(t, err)
+	results, err = flt.Execute(testSlice)
+	require.NoError(t, err)
+	require.Equal(t, testSlice[:1], results)
+}
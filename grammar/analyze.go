@@ -0,0 +1,311 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package grammar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Literal is an atom of an expression, possibly negated by the "not"
+// operators above it.
+type Literal struct {
+	// Expression is the match or collection expression, as found in the
+	// analyzed expression.
+	Expression Expression
+	Negated    bool
+}
+
+func (l Literal) String() string {
+	s := describe(l.Expression)
+	if l.Negated {
+		return fmt.Sprintf("not (%s)", s)
+	}
+	return s
+}
+
+// Conflict explains why a clause of an expression can never be true, or is
+// always true.
+type Conflict struct {
+	// Literals are the literals of the clause responsible for the conflict.
+	Literals []Literal
+	Reason   string
+}
+
+// Analysis is the result of Analyze.
+type Analysis struct {
+	// Unsatisfiable is true when the expression cannot match any datum.
+	Unsatisfiable bool
+
+	// Tautology is true when the expression matches every datum.
+	Tautology bool
+
+	// Conflicts is the witness of the result: for an unsatisfiable expression
+	// it holds the contradiction found in each disjunct of its disjunctive
+	// normal form, and for a tautology the complementary literals found in
+	// each conjunct of its conjunctive normal form.
+	Conflicts []Conflict
+}
+
+// Analyze looks for expressions that can never match, like
+// `Env == "prod" and Env == "dev"`, or always match, like
+// `Env == "prod" or Env != "prod"`. It recognizes conflicting equality, set
+// membership, emptiness and nil checks on the same selector and complementary
+// literals; an expression it cannot decide is reported as neither
+// unsatisfiable nor a tautology. The grammar has no range operators yet so no
+// range reasoning is done.
+//
+// Like Simplify, the analysis assumes the expression evaluates without
+// error. maxClauses limits the size of the normal forms as for ToDNF.
+func Analyze(expr Expression, maxClauses int) (*Analysis, error) {
+	var analysis Analysis
+
+	disjuncts, err := literalClauses(expr, BinaryOpOr, maxClauses)
+	if err != nil {
+		return nil, err
+	}
+	if conflicts, ok := findConflicts(disjuncts, BinaryOpAnd); ok {
+		analysis.Unsatisfiable = true
+		analysis.Conflicts = conflicts
+		return &analysis, nil
+	}
+
+	conjuncts, err := literalClauses(expr, BinaryOpAnd, maxClauses)
+	if err != nil {
+		return nil, err
+	}
+	if conflicts, ok := findConflicts(conjuncts, BinaryOpOr); ok {
+		analysis.Tautology = true
+		analysis.Conflicts = conflicts
+	}
+	return &analysis, nil
+}
+
+// findConflicts looks for a conflict in every clause, clauses being chains of
+// op.
+func findConflicts(clauses [][]Literal, op BinaryOperator) ([]Conflict, bool) {
+	conflicts := make([]Conflict, 0, len(clauses))
+	for _, clause := range clauses {
+		conflict, ok := findConflict(clause, op)
+		if !ok {
+			return nil, false
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, true
+}
+
+func findConflict(clause []Literal, op BinaryOperator) (Conflict, bool) {
+	exprs := make([]Expression, len(clause))
+	negated := make([]Expression, len(clause))
+	for i, lit := range clause {
+		exprs[i] = simplify(lit.Expression, lit.Negated)
+		negated[i] = simplify(lit.Expression, !lit.Negated)
+	}
+
+	for i, lit := range clause {
+		if c, ok := exprs[i].(*ConstantExpression); ok && c.Value == (op == BinaryOpOr) {
+			reason := "is never true"
+			if c.Value {
+				reason = "is always true"
+			}
+			return Conflict{
+				Literals: []Literal{lit},
+				Reason:   fmt.Sprintf("%s %s", lit, reason),
+			}, true
+		}
+	}
+
+	for i := range clause {
+		for j := i + 1; j < len(clause); j++ {
+			if op == BinaryOpAnd && (conflictImplies(exprs[i], negated[j]) || conflictImplies(exprs[j], negated[i])) {
+				return Conflict{
+					Literals: []Literal{clause[i], clause[j]},
+					Reason:   fmt.Sprintf("%s and %s cannot both be true", clause[i], clause[j]),
+				}, true
+			}
+			if op == BinaryOpOr && (conflictImplies(negated[i], exprs[j]) || conflictImplies(negated[j], exprs[i])) {
+				return Conflict{
+					Literals: []Literal{clause[i], clause[j]},
+					Reason:   fmt.Sprintf("%s or %s is always true", clause[i], clause[j]),
+				}, true
+			}
+		}
+	}
+	return Conflict{}, false
+}
+
+// conflictImplies is implies with the rules relating emptiness and nil checks
+// to the values of a selector, which Simplify does not use.
+func conflictImplies(a, b Expression) bool {
+	if implies(a, b) {
+		return true
+	}
+
+	ma, ok1 := a.(*MatchExpression)
+	mb, ok2 := b.(*MatchExpression)
+	if !ok1 || !ok2 || !equalPaths(ma.Selector.Path, mb.Selector.Path) {
+		return false
+	}
+
+	switch {
+	case ma.Operator == MatchIsNil && mb.Operator == MatchIsEmpty:
+		// nil maps and slices are empty
+		return true
+	case (ma.Operator == MatchIsEmpty || ma.Operator == MatchIsNil) && mb.Operator == MatchNotIn:
+		// empty collections contain nothing, and empty strings only contain
+		// the empty string
+		return mb.Value.Raw != ""
+	case ma.Operator == MatchIn && (mb.Operator == MatchIsNotEmpty || mb.Operator == MatchIsNotNil):
+		return ma.Value.Raw != ""
+	}
+
+	// Only strings can be both compared to literals and checked for
+	// emptiness, so a selector equal to non empty literals is not empty and
+	// one equal to the empty string is empty
+	positive, values, ok := equalityValues(ma)
+	if !ok || !positive {
+		return false
+	}
+	switch mb.Operator {
+	case MatchIsNotEmpty:
+		for _, v := range values {
+			if v.Raw == "" {
+				return false
+			}
+		}
+		return true
+	case MatchIsEmpty:
+		for _, v := range values {
+			if v.Raw != "" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// literalClauses works like normalClauses but keeps the atoms of expr
+// untouched.
+func literalClauses(expr Expression, outer BinaryOperator, maxClauses int) ([][]Literal, error) {
+	if maxClauses <= 0 {
+		maxClauses = DefaultMaxClauses
+	}
+
+	var walk func(Expression, bool) ([][]Literal, error)
+	walk = func(expr Expression, negate bool) ([][]Literal, error) {
+		switch node := expr.(type) {
+		case *UnaryExpression:
+			if node.Operator == UnaryOpNot {
+				return walk(node.Operand, !negate)
+			}
+		case *BinaryExpression:
+			op := node.Operator
+			switch {
+			case op != BinaryOpAnd && op != BinaryOpOr:
+				return [][]Literal{{{Expression: expr, Negated: negate}}}, nil
+			case negate && op == BinaryOpAnd:
+				op = BinaryOpOr
+			case negate && op == BinaryOpOr:
+				op = BinaryOpAnd
+			}
+
+			left, err := walk(node.Left, negate)
+			if err != nil {
+				return nil, err
+			}
+			right, err := walk(node.Right, negate)
+			if err != nil {
+				return nil, err
+			}
+
+			if op == outer {
+				if len(left)+len(right) > maxClauses {
+					return nil, fmt.Errorf("%w: more than %d", ErrTooManyClauses, maxClauses)
+				}
+				return append(left, right...), nil
+			}
+
+			if len(left)*len(right) > maxClauses {
+				return nil, fmt.Errorf("%w: %d exceeds %d", ErrTooManyClauses, len(left)*len(right), maxClauses)
+			}
+			clauses := make([][]Literal, 0, len(left)*len(right))
+			for _, l := range left {
+				for _, r := range right {
+					clause := make([]Literal, 0, len(l)+len(r))
+					clause = append(clause, l...)
+					clause = append(clause, r...)
+					clauses = append(clauses, clause)
+				}
+			}
+			return clauses, nil
+		case *ConstantExpression:
+			if (node.Value != negate) == (outer == BinaryOpOr) {
+				return [][]Literal{{}}, nil
+			}
+			return nil, nil
+		}
+		return [][]Literal{{{Expression: expr, Negated: negate}}}, nil
+	}
+
+	return walk(expr, false)
+}
+
+// describe renders the atoms used in conflict explanations
+func describe(expr Expression) string {
+	switch node := expr.(type) {
+	case *MatchExpression:
+		sel := node.Selector.String()
+		switch node.Operator {
+		case MatchEqual:
+			return fmt.Sprintf("%s == %s", sel, strconv.Quote(node.Value.Raw))
+		case MatchNotEqual:
+			return fmt.Sprintf("%s != %s", sel, strconv.Quote(node.Value.Raw))
+		case MatchIn:
+			return fmt.Sprintf("%s in %s", strconv.Quote(node.Value.Raw), sel)
+		case MatchNotIn:
+			return fmt.Sprintf("%s not in %s", strconv.Quote(node.Value.Raw), sel)
+		case MatchIsEmpty:
+			return fmt.Sprintf("%s is empty", sel)
+		case MatchIsNotEmpty:
+			return fmt.Sprintf("%s is not empty", sel)
+		case MatchMatches:
+			return fmt.Sprintf("%s matches %s", sel, strconv.Quote(node.Value.Raw))
+		case MatchNotMatches:
+			return fmt.Sprintf("%s not matches %s", sel, strconv.Quote(node.Value.Raw))
+		case MatchIsNil:
+			return fmt.Sprintf("%s is nil", sel)
+		case MatchIsNotNil:
+			return fmt.Sprintf("%s is not nil", sel)
+		case MatchIsOneOf, MatchIsNotOneOf:
+			values := make([]string, 0, len(node.Values))
+			for _, v := range node.Values {
+				values = append(values, strconv.Quote(v.Raw))
+			}
+			word := "is one of"
+			if node.Operator == MatchIsNotOneOf {
+				word = "is not one of"
+			}
+			return fmt.Sprintf("%s %s [%s]", sel, word, strings.Join(values, ", "))
+		}
+		return fmt.Sprintf("%s %s", sel, node.Operator)
+	case *CollectionExpression:
+		var binding string
+		switch node.NameBinding.Mode {
+		case CollectionBindIndex:
+			binding = node.NameBinding.Index + ", _"
+		case CollectionBindValue:
+			binding = "_, " + node.NameBinding.Value
+		case CollectionBindIndexAndValue:
+			binding = node.NameBinding.Index + ", " + node.NameBinding.Value
+		default:
+			binding = node.NameBinding.Default
+		}
+		return fmt.Sprintf("%s %s as %s { ... }", strings.ToLower(string(node.Op)), node.Selector, binding)
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
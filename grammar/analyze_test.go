@@ -0,0 +1,135 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package grammar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		input         string
+		unsatisfiable bool
+		tautology     bool
+		reasons       []string
+	}
+
+	tests := map[string]testCase{
+		"satisfiable": {
+			input: `Env == "prod" and Region == "eu"`,
+		},
+		"different values": {
+			input:         `Env == "prod" and Env == "dev"`,
+			unsatisfiable: true,
+			reasons:       []string{`Env == "prod" and Env == "dev" cannot both be true`},
+		},
+		"equal after coercion": {
+			input: `Port == 80 and Port == "0x50"`,
+		},
+		"negation": {
+			input:         `Env == "prod" and not (Env == "prod" or Region == "eu")`,
+			unsatisfiable: true,
+			reasons:       []string{`Env == "prod" and not (Env == "prod") cannot both be true`},
+		},
+		"every disjunct": {
+			input:         `(Env == "prod" or Env == "dev") and Env == "test"`,
+			unsatisfiable: true,
+			reasons: []string{
+				`Env == "prod" and Env == "test" cannot both be true`,
+				`Env == "dev" and Env == "test" cannot both be true`,
+			},
+		},
+		"one satisfiable disjunct": {
+			input: `(Env == "prod" or Region == "eu") and Env == "test"`,
+		},
+		"emptiness": {
+			input:         `Tags is empty and "web" in Tags`,
+			unsatisfiable: true,
+			reasons:       []string{`Tags is empty and "web" in Tags cannot both be true`},
+		},
+		"nil": {
+			input:         `Meta is nil and Meta is not empty`,
+			unsatisfiable: true,
+			reasons:       []string{`Meta is nil and Meta is not empty cannot both be true`},
+		},
+		"nil and not nil": {
+			input:         `Meta is nil and not (Meta is nil)`,
+			unsatisfiable: true,
+			reasons:       []string{`Meta is nil and not (Meta is nil) cannot both be true`},
+		},
+		"equality and emptiness": {
+			input:         `Env == "a" and Env is empty`,
+			unsatisfiable: true,
+			reasons:       []string{`Env == "a" and Env is empty cannot both be true`},
+		},
+		"emptiness and equality": {
+			input:         `Env is empty and Env == "a"`,
+			unsatisfiable: true,
+			reasons:       []string{`Env is empty and Env == "a" cannot both be true`},
+		},
+		"empty string and emptiness": {
+			input:         `Env == "" and Env is not empty`,
+			unsatisfiable: true,
+			reasons:       []string{`Env == "" and Env is not empty cannot both be true`},
+		},
+		"equality to the empty string": {
+			input: `Env == "" and Env is empty`,
+		},
+		"tautology of equality and emptiness": {
+			input:     `Env != "a" or Env is not empty`,
+			tautology: true,
+			reasons:   []string{`Env != "a" or Env is not empty is always true`},
+		},
+		"collection": {
+			input:         `any Items as i { i == 1 and i == 2 }`,
+			unsatisfiable: true,
+			reasons:       []string{`any Items as i { ... } is never true`},
+		},
+		"tautology": {
+			input:     `Env == "prod" or Region == "eu" or Env != "prod"`,
+			tautology: true,
+			reasons:   []string{`Env == "prod" or Env != "prod" is always true`},
+		},
+		"tautology of emptiness": {
+			input:     `not (Tags is empty) or Tags is empty`,
+			tautology: true,
+			reasons:   []string{`not (Tags is empty) or Tags is empty is always true`},
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			input := mustParse(t, tcase.input)
+			analysis, err := Analyze(input, 0)
+			require.NoError(t, err)
+			require.Equal(t, tcase.unsatisfiable, analysis.Unsatisfiable)
+			require.Equal(t, tcase.tautology, analysis.Tautology)
+
+			var reasons []string
+			for _, conflict := range analysis.Conflicts {
+				reasons = append(reasons, conflict.Reason)
+			}
+			require.Equal(t, tcase.reasons, reasons)
+		})
+	}
+}
+
+func TestAnalyze_WitnessReferencesInput(t *testing.T) {
+	t.Parallel()
+
+	input := mustParse(t, `Env == "prod" and Env == "dev"`).(*BinaryExpression)
+	analysis, err := Analyze(input, 0)
+	require.NoError(t, err)
+	require.True(t, analysis.Unsatisfiable)
+	require.Len(t, analysis.Conflicts, 1)
+	require.Equal(t, []Literal{{Expression: input.Left}, {Expression: input.Right}}, analysis.Conflicts[0].Literals)
+	require.Same(t, input.Left, analysis.Conflicts[0].Literals[0].Expression)
+}
@@ -61,6 +61,11 @@ const (
 	MatchNotMatches
 	MatchIsNil
 	MatchIsNotNil
+	// MatchIsOneOf and MatchIsNotOneOf have no syntax of their own, they are
+	// produced by Simplify when merging equality checks on the same selector
+	// and use MatchExpression.Values instead of MatchExpression.Value.
+	MatchIsOneOf
+	MatchIsNotOneOf
 )
 
 func (op MatchOperator) String() string {
@@ -85,6 +90,10 @@ func (op MatchOperator) String() string {
 		return "Is Nil"
 	case MatchIsNotNil:
 		return "Not Nil"
+	case MatchIsOneOf:
+		return "Is One Of"
+	case MatchIsNotOneOf:
+		return "Is Not One Of"
 	default:
 		return "UNKNOWN"
 	}
@@ -125,6 +134,12 @@ func (op MatchOperator) NotPresentDisposition() bool {
 	case MatchIsNotNil:
 		// M["x"] is not nil is false. Missing keys have no value.
 		return false
+	case MatchIsOneOf:
+		// M["x"] is one of <anything> is false. Nothing is equal to a missing key
+		return false
+	case MatchIsNotOneOf:
+		// M["x"] is not one of <anything> is true. Nothing is equal to a missing key
+		return true
 	default:
 		// Should never be reached as every operator should explicitly define its
 		// behavior.
@@ -179,6 +194,15 @@ type MatchExpression struct {
 	Selector Selector
 	Operator MatchOperator
 	Value    *MatchValue
+	// Values holds the candidates of MatchIsOneOf and MatchIsNotOneOf
+	Values []*MatchValue
+}
+
+// ConstantExpression is an expression whose result does not depend on the
+// datum. It is never produced by the parser but can be the result of
+// transformations like Simplify.
+type ConstantExpression struct {
+	Value bool
 }
 
 func (expr *UnaryExpression) ExpressionDump(w io.Writer, indent string, level int) {
@@ -200,11 +224,25 @@ func (expr *MatchExpression) ExpressionDump(w io.Writer, indent string, level in
 	switch expr.Operator {
 	case MatchEqual, MatchNotEqual, MatchIn, MatchNotIn:
 		fmt.Fprintf(w, "%[1]s%[3]s {\n%[2]sSelector: %[4]v\n%[2]sValue: %[5]q\n%[1]s}\n", strings.Repeat(indent, level), strings.Repeat(indent, level+1), expr.Operator.String(), expr.Selector, expr.Value.Raw)
+	case MatchIsOneOf, MatchIsNotOneOf:
+		values := make([]string, 0, len(expr.Values))
+		for _, v := range expr.Values {
+			values = append(values, fmt.Sprintf("%q", v.Raw))
+		}
+		fmt.Fprintf(w, "%[1]s%[3]s {\n%[2]sSelector: %[4]v\n%[2]sValues: [%[5]s]\n%[1]s}\n", strings.Repeat(indent, level), strings.Repeat(indent, level+1), expr.Operator.String(), expr.Selector, strings.Join(values, ", "))
 	default:
 		fmt.Fprintf(w, "%[1]s%[3]s {\n%[2]sSelector: %[4]v\n%[1]s}\n", strings.Repeat(indent, level), strings.Repeat(indent, level+1), expr.Operator.String(), expr.Selector)
 	}
 }
 
+func (expr *ConstantExpression) ExpressionDump(w io.Writer, indent string, level int) {
+	if expr.Value {
+		fmt.Fprintf(w, "%sTrue\n", strings.Repeat(indent, level))
+	} else {
+		fmt.Fprintf(w, "%sFalse\n", strings.Repeat(indent, level))
+	}
+}
+
 type CollectionBindMode string
 
 const (
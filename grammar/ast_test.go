@@ -50,6 +50,14 @@ func TestAST_Dump(t *testing.T) {
 			expr:     &MatchExpression{Selector: Selector{Type: SelectorTypeBexpr, Path: []string{"foo", "bar"}}, Operator: MatchIsNil, Value: nil},
 			expected: "Is Nil {\n   Selector: foo.bar\n}\n",
 		},
+		"MatchIsOneOf": {
+			expr:     &MatchExpression{Selector: Selector{Type: SelectorTypeBexpr, Path: []string{"foo", "bar"}}, Operator: MatchIsOneOf, Values: []*MatchValue{{Raw: "baz"}, {Raw: "qux"}}},
+			expected: "Is One Of {\n   Selector: foo.bar\n   Values: [\"baz\", \"qux\"]\n}\n",
+		},
+		"Constant": {
+			expr:     &ConstantExpression{Value: true},
+			expected: "True\n",
+		},
 		"UnaryOpNot": {
 			expr:     &UnaryExpression{Operator: UnaryOpNot, Operand: &MatchExpression{Selector: Selector{Type: SelectorTypeBexpr, Path: []string{"foo", "bar"}}, Operator: MatchIsEmpty, Value: nil}},
 			expected: "Not {\n   Is Empty {\n      Selector: foo.bar\n   }\n}\n",
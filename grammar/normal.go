@@ -0,0 +1,151 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package grammar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxClauses is the number of clauses ToDNF and ToCNF allow when no
+// limit is given.
+const DefaultMaxClauses = 10000
+
+// ErrTooManyClauses is returned when converting an expression to a normal
+// form would produce more clauses than allowed.
+var ErrTooManyClauses = errors.New("too many clauses in normal form")
+
+// ToDNF converts expr to disjunctive normal form: a chain of "or" whose
+// operands are chains of "and". Match, collection and constant expressions
+// are treated as atoms, negations are pushed down to them as Simplify does.
+//
+// The conversion can grow the expression exponentially, an error wrapping
+// ErrTooManyClauses is returned if more than maxClauses disjuncts would be
+// produced. When maxClauses is not positive, DefaultMaxClauses is used.
+func ToDNF(expr Expression, maxClauses int) (Expression, error) {
+	return toNormalForm(expr, BinaryOpOr, maxClauses)
+}
+
+// ToCNF converts expr to conjunctive normal form: a chain of "and" whose
+// operands are chains of "or". It works like ToDNF with maxClauses limiting
+// the number of conjuncts.
+func ToCNF(expr Expression, maxClauses int) (Expression, error) {
+	return toNormalForm(expr, BinaryOpAnd, maxClauses)
+}
+
+func toNormalForm(expr Expression, outer BinaryOperator, maxClauses int) (Expression, error) {
+	inner := BinaryOpAnd
+	if outer == BinaryOpAnd {
+		inner = BinaryOpOr
+	}
+
+	clauses, err := normalClauses(expr, outer, maxClauses)
+	if err != nil {
+		return nil, err
+	}
+
+	// The value of a chain of outer with no operand
+	empty := outer == BinaryOpAnd
+
+	var operands []Expression
+	// The dumps of expressions leave some values out, like the patterns of
+	// matches operators, so they only group the candidate duplicates
+	seen := make(map[string][]Expression)
+	for _, clause := range clauses {
+		var operand Expression
+		if len(clause) == 0 {
+			operand = &ConstantExpression{Value: !empty}
+		} else {
+			operand = combine(inner, clause)
+		}
+
+		if c, ok := operand.(*ConstantExpression); ok {
+			if c.Value == empty {
+				continue
+			}
+			return operand, nil
+		}
+
+		var key bytes.Buffer
+		operand.ExpressionDump(&key, " ", 0)
+		if !containsExpression(seen[key.String()], operand) {
+			seen[key.String()] = append(seen[key.String()], operand)
+			operands = append(operands, operand)
+		}
+	}
+
+	if len(operands) == 0 {
+		return &ConstantExpression{Value: empty}, nil
+	}
+	return chain(outer, operands), nil
+}
+
+func containsExpression(exprs []Expression, expr Expression) bool {
+	for _, e := range exprs {
+		if equalExpressions(e, expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalClauses returns the clauses of the normal form of expr as a chain of
+// outer of chains of the other binary operator.
+func normalClauses(expr Expression, outer BinaryOperator, maxClauses int) ([][]Expression, error) {
+	if maxClauses <= 0 {
+		maxClauses = DefaultMaxClauses
+	}
+
+	var walk func(Expression) ([][]Expression, error)
+	walk = func(expr Expression) ([][]Expression, error) {
+		switch node := expr.(type) {
+		case *BinaryExpression:
+			if node.Operator != BinaryOpAnd && node.Operator != BinaryOpOr {
+				break
+			}
+			left, err := walk(node.Left)
+			if err != nil {
+				return nil, err
+			}
+			right, err := walk(node.Right)
+			if err != nil {
+				return nil, err
+			}
+
+			if node.Operator == outer {
+				if len(left)+len(right) > maxClauses {
+					return nil, fmt.Errorf("%w: more than %d", ErrTooManyClauses, maxClauses)
+				}
+				return append(left, right...), nil
+			}
+
+			// Distribute the inner operator over the outer one
+			if len(left)*len(right) > maxClauses {
+				return nil, fmt.Errorf("%w: %d exceeds %d", ErrTooManyClauses, len(left)*len(right), maxClauses)
+			}
+			clauses := make([][]Expression, 0, len(left)*len(right))
+			for _, l := range left {
+				for _, r := range right {
+					clause := make([]Expression, 0, len(l)+len(r))
+					clause = append(clause, l...)
+					clause = append(clause, r...)
+					clauses = append(clauses, clause)
+				}
+			}
+			return clauses, nil
+		case *ConstantExpression:
+			// true is a single empty conjunction in disjunctive normal form
+			// while false is a single empty disjunction in conjunctive normal
+			// form.
+			if node.Value == (outer == BinaryOpOr) {
+				return [][]Expression{{}}, nil
+			}
+			return nil, nil
+		}
+		return [][]Expression{{expr}}, nil
+	}
+
+	return walk(Simplify(expr))
+}
@@ -0,0 +1,118 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package grammar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalForms(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		input string
+		dnf   string
+		cnf   string
+	}
+
+	tests := map[string]testCase{
+		"atom": {
+			input: "a == 1",
+			dnf:   "a == 1",
+			cnf:   "a == 1",
+		},
+		"distribute and": {
+			input: "a == 1 and (b == 2 or c == 3)",
+			dnf:   "(a == 1 and b == 2) or (a == 1 and c == 3)",
+			cnf:   "a == 1 and (b == 2 or c == 3)",
+		},
+		"distribute or": {
+			input: "a == 1 or (b == 2 and c == 3)",
+			dnf:   "a == 1 or (b == 2 and c == 3)",
+			cnf:   "(a == 1 or b == 2) and (a == 1 or c == 3)",
+		},
+		"negation": {
+			input: "not (a == 1 or b is empty) or c == 3",
+			dnf:   "(a != 1 and b is not empty) or c == 3",
+			cnf:   "(a != 1 or c == 3) and (b is not empty or c == 3)",
+		},
+		"collections are atoms": {
+			input: "((any a as v { v == 1 or v == 2 }) or b == 1) and c == 3",
+			dnf:   "((any a as v { v == 1 or v == 2 }) and c == 3) or (b == 1 and c == 3)",
+			cnf:   "((any a as v { v == 1 or v == 2 }) or b == 1) and c == 3",
+		},
+		"contradictory clauses are dropped": {
+			input: "(a == 1 or b == 2) and (a == 3 or c == 4)",
+			dnf:   "(a == 1 and c == 4) or (b == 2 and a == 3) or (b == 2 and c == 4)",
+			cnf:   "(a == 1 or b == 2) and (a == 3 or c == 4)",
+		},
+		"matches patterns are kept": {
+			input: `a matches "x" or a matches "y"`,
+			dnf:   `a matches "x" or a matches "y"`,
+			cnf:   `a matches "x" or a matches "y"`,
+		},
+		"clauses differing by a pattern are kept": {
+			input: `(a matches "x" or b == 1) and (a matches "y" or b == 1)`,
+			dnf:   `(a matches "x" and a matches "y") or (a matches "x" and b == 1) or (b == 1 and a matches "y") or b == 1`,
+			cnf:   `(a matches "x" or b == 1) and (a matches "y" or b == 1)`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			input := mustParse(t, tcase.input)
+
+			dnf, err := ToDNF(input, 0)
+			require.NoError(t, err)
+			require.Equal(t, Simplify(mustParse(t, tcase.dnf)), dnf)
+
+			cnf, err := ToCNF(input, 0)
+			require.NoError(t, err)
+			require.Equal(t, Simplify(mustParse(t, tcase.cnf)), cnf)
+		})
+	}
+}
+
+func TestNormalForms_Constants(t *testing.T) {
+	t.Parallel()
+
+	dnf, err := ToDNF(mustParse(t, "a == 1 and a == 2"), 0)
+	require.NoError(t, err)
+	require.Equal(t, &ConstantExpression{Value: false}, dnf)
+
+	cnf, err := ToCNF(mustParse(t, "a == 1 or a != 1"), 0)
+	require.NoError(t, err)
+	require.Equal(t, &ConstantExpression{Value: true}, cnf)
+}
+
+func TestNormalForms_MaxClauses(t *testing.T) {
+	t.Parallel()
+
+	// (a0 == 0 or b0 == 0) and (a1 == 1 or b1 == 1) and ... has 2^n
+	// disjuncts in disjunctive normal form
+	var terms []string
+	for i := 0; i < 12; i++ {
+		terms = append(terms, fmt.Sprintf("(a%[1]d == %[1]d or b%[1]d == %[1]d)", i))
+	}
+	input := mustParse(t, strings.Join(terms, " and "))
+
+	_, err := ToDNF(input, 0)
+	require.NoError(t, err)
+
+	_, err = ToDNF(input, 1000)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrTooManyClauses))
+
+	cnf, err := ToCNF(input, 1000)
+	require.NoError(t, err)
+	require.Len(t, Operands(cnf, BinaryOpAnd), 12)
+}
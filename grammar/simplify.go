@@ -0,0 +1,485 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package grammar
+
+import (
+	"strconv"
+)
+
+var negatedMatchOperators = map[MatchOperator]MatchOperator{
+	MatchEqual:      MatchNotEqual,
+	MatchNotEqual:   MatchEqual,
+	MatchIn:         MatchNotIn,
+	MatchNotIn:      MatchIn,
+	MatchIsEmpty:    MatchIsNotEmpty,
+	MatchIsNotEmpty: MatchIsEmpty,
+	MatchMatches:    MatchNotMatches,
+	MatchNotMatches: MatchMatches,
+	MatchIsNil:      MatchIsNotNil,
+	MatchIsNotNil:   MatchIsNil,
+	MatchIsOneOf:    MatchIsNotOneOf,
+	MatchIsNotOneOf: MatchIsOneOf,
+}
+
+// Simplify returns an expression equivalent to expr in a canonical form:
+//
+//   - chains of "and" and "or" are flattened and rebuilt leaning to the right,
+//     the way the parser builds them
+//   - negations are pushed down to the match and collection expressions using
+//     De Morgan's laws so no "not" remains
+//   - clauses duplicating or implied by a previous clause are removed
+//   - clauses on the same selector whose literals contradict each other, or
+//     always hold, are folded into a ConstantExpression
+//   - adjacent equality checks on the same selector are merged into a
+//     MatchIsOneOf expression, and inequality checks into MatchIsNotOneOf
+//
+// The order of the remaining clauses is preserved so that the simplified
+// expression returns the same result as expr for any datum expr can be
+// evaluated against without error. Removing clauses may however let it
+// succeed on data where expr would return an error. expr is not modified.
+func Simplify(expr Expression) Expression {
+	return simplify(expr, false)
+}
+
+func simplify(expr Expression, negate bool) Expression {
+	switch node := expr.(type) {
+	case *UnaryExpression:
+		if node.Operator == UnaryOpNot {
+			return simplify(node.Operand, !negate)
+		}
+		return negateIf(&UnaryExpression{Operator: node.Operator, Operand: simplify(node.Operand, false)}, negate)
+	case *BinaryExpression:
+		return simplifyBinary(node, negate)
+	case *MatchExpression:
+		match := copyMatch(node)
+		if negate {
+			op, ok := negatedMatchOperators[node.Operator]
+			if !ok {
+				return negateIf(match, true)
+			}
+			match.Operator = op
+		}
+		return match
+	case *CollectionExpression:
+		return simplifyCollection(node, negate)
+	case *ConstantExpression:
+		return &ConstantExpression{Value: node.Value != negate}
+	default:
+		return negateIf(expr, negate)
+	}
+}
+
+func negateIf(expr Expression, negate bool) Expression {
+	if !negate {
+		return expr
+	}
+	return &UnaryExpression{Operator: UnaryOpNot, Operand: expr}
+}
+
+func copyMatch(expr *MatchExpression) *MatchExpression {
+	match := &MatchExpression{
+		Selector: copySelector(expr.Selector),
+		Operator: expr.Operator,
+	}
+	if expr.Value != nil {
+		match.Value = &MatchValue{Raw: expr.Value.Raw}
+	}
+	for _, v := range expr.Values {
+		match.Values = append(match.Values, &MatchValue{Raw: v.Raw})
+	}
+	return match
+}
+
+func copySelector(sel Selector) Selector {
+	return Selector{Type: sel.Type, Path: append([]string(nil), sel.Path...)}
+}
+
+func simplifyCollection(expr *CollectionExpression, negate bool) Expression {
+	// not all X { p } is equivalent to any X { not p } and the other way
+	// around, including for empty and missing collections
+	op := expr.Op
+	if negate {
+		switch op {
+		case CollectionOpAll:
+			op = CollectionOpAny
+		case CollectionOpAny:
+			op = CollectionOpAll
+		default:
+			return negateIf(simplifyCollection(expr, false), true)
+		}
+	}
+
+	inner := simplify(expr.Inner, negate)
+	if c, ok := inner.(*ConstantExpression); ok {
+		switch {
+		case op == CollectionOpAny && !c.Value:
+			return &ConstantExpression{Value: false}
+		case op == CollectionOpAll && c.Value:
+			return &ConstantExpression{Value: true}
+		case op == CollectionOpAny:
+			return &MatchExpression{Selector: copySelector(expr.Selector), Operator: MatchIsNotEmpty}
+		case op == CollectionOpAll:
+			return &MatchExpression{Selector: copySelector(expr.Selector), Operator: MatchIsEmpty}
+		}
+	}
+
+	return &CollectionExpression{
+		Op:          op,
+		Selector:    copySelector(expr.Selector),
+		Inner:       inner,
+		NameBinding: expr.NameBinding,
+	}
+}
+
+func simplifyBinary(expr *BinaryExpression, negate bool) Expression {
+	op := expr.Operator
+	switch {
+	case op != BinaryOpAnd && op != BinaryOpOr:
+		return negateIf(&BinaryExpression{
+			Left:     simplify(expr.Left, false),
+			Operator: op,
+			Right:    simplify(expr.Right, false),
+		}, negate)
+	case negate && op == BinaryOpAnd:
+		op = BinaryOpOr
+	case negate && op == BinaryOpOr:
+		op = BinaryOpAnd
+	}
+
+	var operands []Expression
+	operands = append(operands, Operands(simplify(expr.Left, negate), op)...)
+	operands = append(operands, Operands(simplify(expr.Right, negate), op)...)
+	return combine(op, operands)
+}
+
+// Operands returns the operands of a chain of op, like the disjuncts of an
+// expression in disjunctive normal form. An expression that is not a chain of
+// op is returned as the only operand.
+func Operands(expr Expression, op BinaryOperator) []Expression {
+	if binary, ok := expr.(*BinaryExpression); ok && binary.Operator == op {
+		return append(Operands(binary.Left, op), Operands(binary.Right, op)...)
+	}
+	return []Expression{expr}
+}
+
+// chain builds a right-leaning chain of op from operands
+func chain(op BinaryOperator, operands []Expression) Expression {
+	result := operands[len(operands)-1]
+	for i := len(operands) - 2; i >= 0; i-- {
+		result = &BinaryExpression{Left: operands[i], Operator: op, Right: result}
+	}
+	return result
+}
+
+// combine simplifies the already simplified operands of a chain of op
+func combine(op BinaryOperator, operands []Expression) Expression {
+	// The value deciding the result of the chain on its own, false for "and"
+	// and true for "or"
+	decisive := op == BinaryOpOr
+
+	var kept []Expression
+	for _, operand := range operands {
+		if c, ok := operand.(*ConstantExpression); ok {
+			if c.Value == decisive {
+				return &ConstantExpression{Value: decisive}
+			}
+			continue
+		}
+		kept = append(kept, operand)
+	}
+
+	for {
+		n := len(kept)
+		kept = removeRedundant(op, kept)
+		if decided(op, kept) {
+			return &ConstantExpression{Value: decisive}
+		}
+		kept = mergeAdjacent(op, kept)
+		if len(kept) == n {
+			break
+		}
+	}
+
+	if len(kept) == 0 {
+		return &ConstantExpression{Value: !decisive}
+	}
+	return chain(op, kept)
+}
+
+// removeRedundant removes the operands that do not change the result of the
+// chain because of a previous operand: in "a and b", b is redundant if a
+// implies it while in "a or b" it is redundant if it implies a.
+func removeRedundant(op BinaryOperator, operands []Expression) []Expression {
+	var kept []Expression
+	for _, operand := range operands {
+		redundant := false
+		for _, previous := range kept {
+			if (op == BinaryOpAnd && implies(previous, operand)) || (op == BinaryOpOr && implies(operand, previous)) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			kept = append(kept, operand)
+		}
+	}
+	return kept
+}
+
+// decided reports whether two operands of a chain of "and" contradict each
+// other, or two operands of a chain of "or" cover every possible case.
+func decided(op BinaryOperator, operands []Expression) bool {
+	negated := make([]Expression, len(operands))
+	for i, operand := range operands {
+		negated[i] = simplify(operand, true)
+	}
+	for i := range operands {
+		for j := i + 1; j < len(operands); j++ {
+			if op == BinaryOpAnd && implies(operands[i], negated[j]) {
+				return true
+			}
+			if op == BinaryOpOr && implies(negated[i], operands[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeAdjacent merges adjacent equality checks on the same selector in a
+// chain of "or", and adjacent inequality checks in a chain of "and".
+func mergeAdjacent(op BinaryOperator, operands []Expression) []Expression {
+	positive := op == BinaryOpOr
+
+	var kept []Expression
+	for _, operand := range operands {
+		if len(kept) > 0 {
+			prev, ok1 := kept[len(kept)-1].(*MatchExpression)
+			cur, ok2 := operand.(*MatchExpression)
+			if ok1 && ok2 && equalPaths(prev.Selector.Path, cur.Selector.Path) {
+				prevPositive, prevValues, ok1 := equalityValues(prev)
+				curPositive, curValues, ok2 := equalityValues(cur)
+				if ok1 && ok2 && prevPositive == positive && curPositive == positive {
+					values := append(append([]*MatchValue(nil), prevValues...), curValues...)
+					kept[len(kept)-1] = equalityMatch(prev.Selector, positive, values)
+					continue
+				}
+			}
+		}
+		kept = append(kept, operand)
+	}
+	return kept
+}
+
+// equalityValues returns the literals of an equality or inequality check,
+// and whether the selector must be equal to one of them
+func equalityValues(expr *MatchExpression) (bool, []*MatchValue, bool) {
+	switch expr.Operator {
+	case MatchEqual:
+		return true, []*MatchValue{expr.Value}, true
+	case MatchIsOneOf:
+		return true, expr.Values, true
+	case MatchNotEqual:
+		return false, []*MatchValue{expr.Value}, true
+	case MatchIsNotOneOf:
+		return false, expr.Values, true
+	default:
+		return false, nil, false
+	}
+}
+
+// equalityMatch builds the match expression checking whether sel is equal to
+// one of values, or to none of them
+func equalityMatch(sel Selector, positive bool, values []*MatchValue) *MatchExpression {
+	var unique []*MatchValue
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if !seen[v.Raw] {
+			seen[v.Raw] = true
+			unique = append(unique, &MatchValue{Raw: v.Raw})
+		}
+	}
+
+	match := &MatchExpression{Selector: copySelector(sel)}
+	switch {
+	case len(unique) == 1 && positive:
+		match.Operator, match.Value = MatchEqual, unique[0]
+	case len(unique) == 1:
+		match.Operator, match.Value = MatchNotEqual, unique[0]
+	case positive:
+		match.Operator, match.Values = MatchIsOneOf, unique
+	default:
+		match.Operator, match.Values = MatchIsNotOneOf, unique
+	}
+	return match
+}
+
+// implies reports whether a being true guarantees that b is true. It only
+// recognizes simple cases and returns false when it cannot tell.
+func implies(a, b Expression) bool {
+	if equalExpressions(a, b) {
+		return true
+	}
+	if c, ok := a.(*ConstantExpression); ok && !c.Value {
+		return true
+	}
+	if c, ok := b.(*ConstantExpression); ok && c.Value {
+		return true
+	}
+
+	if binary, ok := a.(*BinaryExpression); ok {
+		switch binary.Operator {
+		case BinaryOpOr:
+			return implies(binary.Left, b) && implies(binary.Right, b)
+		case BinaryOpAnd:
+			if implies(binary.Left, b) || implies(binary.Right, b) {
+				return true
+			}
+		}
+	}
+	if binary, ok := b.(*BinaryExpression); ok {
+		switch binary.Operator {
+		case BinaryOpOr:
+			return implies(a, binary.Left) || implies(a, binary.Right)
+		case BinaryOpAnd:
+			return implies(a, binary.Left) && implies(a, binary.Right)
+		}
+	}
+
+	ma, ok1 := a.(*MatchExpression)
+	mb, ok2 := b.(*MatchExpression)
+	if !ok1 || !ok2 || !equalPaths(ma.Selector.Path, mb.Selector.Path) {
+		return false
+	}
+
+	aPositive, aValues, ok1 := equalityValues(ma)
+	bPositive, bValues, ok2 := equalityValues(mb)
+	if !ok1 || !ok2 {
+		return false
+	}
+	switch {
+	case aPositive && bPositive:
+		// x is one of S implies x is one of T when S is a subset of T
+		return subsetOf(aValues, bValues)
+	case aPositive && !bPositive:
+		// x is one of S implies x is not one of T when no element of S is
+		// equal to an element of T
+		for _, av := range aValues {
+			for _, bv := range bValues {
+				if !literalsDiffer(av.Raw, bv.Raw) {
+					return false
+				}
+			}
+		}
+		return true
+	case !aPositive && !bPositive:
+		// x is not one of S implies x is not one of T when T is a subset of S
+		return subsetOf(bValues, aValues)
+	default:
+		return false
+	}
+}
+
+func subsetOf(a, b []*MatchValue) bool {
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av.Raw == bv.Raw {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// literalsDiffer reports whether two literals can never be considered equal,
+// whatever the type they get coerced to during evaluation.
+func literalsDiffer(a, b string) bool {
+	if a == b {
+		return false
+	}
+	if x, err := strconv.ParseBool(a); err == nil {
+		if y, err := strconv.ParseBool(b); err == nil && x == y {
+			return false
+		}
+	}
+	if x, err := strconv.ParseInt(a, 0, 64); err == nil {
+		if y, err := strconv.ParseInt(b, 0, 64); err == nil && x == y {
+			return false
+		}
+	}
+	if x, err := strconv.ParseUint(a, 0, 64); err == nil {
+		if y, err := strconv.ParseUint(b, 0, 64); err == nil && x == y {
+			return false
+		}
+	}
+	if x, err := strconv.ParseFloat(a, 32); err == nil {
+		if y, err := strconv.ParseFloat(b, 32); err == nil && float32(x) == float32(y) {
+			return false
+		}
+	}
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil && x == y {
+			return false
+		}
+	}
+	return true
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalValues(a, b *MatchValue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Raw == b.Raw
+}
+
+// equalExpressions reports whether a and b are structurally identical,
+// ignoring how their selectors were written.
+func equalExpressions(a, b Expression) bool {
+	switch x := a.(type) {
+	case *UnaryExpression:
+		y, ok := b.(*UnaryExpression)
+		return ok && x.Operator == y.Operator && equalExpressions(x.Operand, y.Operand)
+	case *BinaryExpression:
+		y, ok := b.(*BinaryExpression)
+		return ok && x.Operator == y.Operator && equalExpressions(x.Left, y.Left) && equalExpressions(x.Right, y.Right)
+	case *MatchExpression:
+		y, ok := b.(*MatchExpression)
+		if !ok || x.Operator != y.Operator || !equalPaths(x.Selector.Path, y.Selector.Path) ||
+			!equalValues(x.Value, y.Value) || len(x.Values) != len(y.Values) {
+			return false
+		}
+		for i := range x.Values {
+			if !equalValues(x.Values[i], y.Values[i]) {
+				return false
+			}
+		}
+		return true
+	case *CollectionExpression:
+		y, ok := b.(*CollectionExpression)
+		return ok && x.Op == y.Op && x.NameBinding == y.NameBinding &&
+			equalPaths(x.Selector.Path, y.Selector.Path) && equalExpressions(x.Inner, y.Inner)
+	case *ConstantExpression:
+		y, ok := b.(*ConstantExpression)
+		return ok && x.Value == y.Value
+	default:
+		return false
+	}
+}
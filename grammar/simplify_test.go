@@ -0,0 +1,192 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package grammar
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustParse(t testing.TB, input string) Expression {
+	t.Helper()
+	expr, err := Parse("", []byte(input))
+	require.NoError(t, err)
+	return expr.(Expression)
+}
+
+func TestSimplify(t *testing.T) {
+	t.Parallel()
+
+	sel := func(path ...string) Selector {
+		return Selector{Type: SelectorTypeBexpr, Path: path}
+	}
+	values := func(raws ...string) []*MatchValue {
+		var vs []*MatchValue
+		for _, raw := range raws {
+			vs = append(vs, &MatchValue{Raw: raw})
+		}
+		return vs
+	}
+
+	type testCase struct {
+		input string
+		// expected is parsed when set, otherwise expectedExpr is used
+		expected     string
+		expectedExpr Expression
+	}
+
+	tests := map[string]testCase{
+		"unchanged": {
+			input:    "a == 1 and (b == 2 or c is empty)",
+			expected: "a == 1 and (b == 2 or c is empty)",
+		},
+		"emptiness is not related to other checks": {
+			input:    `a is nil and a is empty and "x" not in a and a == "y"`,
+			expected: `a is nil and a is empty and "x" not in a and a == "y"`,
+		},
+		"flatten left-leaning chains": {
+			input:    "((a == 1 and b == 2) and c == 3) and d == 4",
+			expected: "a == 1 and b == 2 and c == 3 and d == 4",
+		},
+		"double negation": {
+			input:    "not (not a == 1)",
+			expected: "a == 1",
+		},
+		"De Morgan and": {
+			input:    "not (a == 1 and b is empty)",
+			expected: "a != 1 or b is not empty",
+		},
+		"De Morgan or": {
+			input:    "not (a in b or c matches `x` or d is nil)",
+			expected: "a not in b and c not matches `x` and d is not nil",
+		},
+		"negated collection": {
+			input:    "not (all a as v { v == 1 and b != 2 })",
+			expected: "any a as v { v != 1 or b == 2 }",
+		},
+		"duplicates": {
+			input:    "a == 1 and b == 2 and a == 1 and (b == 2)",
+			expected: "a == 1 and b == 2",
+		},
+		"duplicates with different selector syntax": {
+			input:    `a.b == 1 or "/a/b" == 1`,
+			expected: "a.b == 1",
+		},
+		"absorption and": {
+			input:    "a == 1 and (a == 1 or b == 2)",
+			expected: "a == 1",
+		},
+		"absorption or": {
+			input:    "a == 1 or (b == 2 and a == 1)",
+			expected: "a == 1",
+		},
+		"implied inequality": {
+			input:    "a == 1 and a != 2",
+			expected: "a == 1",
+		},
+		"literals equal after coercion are kept": {
+			input:    `a == 1 and a != "0x1"`,
+			expected: `a == 1 and a != "0x1"`,
+		},
+		"contradiction": {
+			input:        `a == "prod" and b == 1 and a == "dev"`,
+			expectedExpr: &ConstantExpression{Value: false},
+		},
+		"contradiction with negation": {
+			input:        `a is empty and not a is empty`,
+			expectedExpr: &ConstantExpression{Value: false},
+		},
+		"tautology": {
+			input:        `a == 1 or b == 2 or a != 1`,
+			expectedExpr: &ConstantExpression{Value: true},
+		},
+		"tautology of inequalities": {
+			input:        `a != 1 or a != 2`,
+			expectedExpr: &ConstantExpression{Value: true},
+		},
+		"folded subexpression": {
+			input:    `c == 3 or (a == 1 and a == 2)`,
+			expected: "c == 3",
+		},
+		"equality chain": {
+			input: `a == 1 or a == 2 or a == 1 or a == 3`,
+			expectedExpr: &MatchExpression{
+				Selector: sel("a"),
+				Operator: MatchIsOneOf,
+				Values:   values("1", "2", "3"),
+			},
+		},
+		"negated equality chain": {
+			input: `not (a == x or a == y) and b == 1`,
+			expectedExpr: &BinaryExpression{
+				Operator: BinaryOpAnd,
+				Left: &MatchExpression{
+					Selector: sel("a"),
+					Operator: MatchIsNotOneOf,
+					Values:   values("x", "y"),
+				},
+				Right: &MatchExpression{Selector: sel("b"), Operator: MatchEqual, Value: &MatchValue{Raw: "1"}},
+			},
+		},
+		"non adjacent equalities are not merged": {
+			input:    `a == 1 or b == 2 or a == 3`,
+			expected: `a == 1 or b == 2 or a == 3`,
+		},
+		"set membership absorbs equality": {
+			input: `(a == 1 or a == 2) and (a == 1 or a == 2 or a == 3)`,
+			expectedExpr: &MatchExpression{
+				Selector: sel("a"),
+				Operator: MatchIsOneOf,
+				Values:   values("1", "2"),
+			},
+		},
+		"collection with false body": {
+			input:        `any a as v { v == 1 and v == 2 }`,
+			expectedExpr: &ConstantExpression{Value: false},
+		},
+		"collection with true body": {
+			input:    `any a as v { v == 1 or v != 1 }`,
+			expected: `a is not empty`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			input := mustParse(t, tcase.input)
+			var before bytes.Buffer
+			input.ExpressionDump(&before, "  ", 0)
+
+			expected := tcase.expectedExpr
+			if tcase.expected != "" {
+				expected = mustParse(t, tcase.expected)
+			}
+			simplified := Simplify(input)
+			require.Equal(t, expected, simplified)
+
+			// Simplify must be idempotent and leave its input untouched
+			require.Equal(t, simplified, Simplify(simplified))
+			var after bytes.Buffer
+			input.ExpressionDump(&after, "  ", 0)
+			require.Equal(t, before.String(), after.String())
+		})
+	}
+}
+
+func TestLiteralsDiffer(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, literalsDiffer("a", "a"))
+	require.True(t, literalsDiffer("a", "b"))
+	require.False(t, literalsDiffer("1", "0x1"))
+	require.False(t, literalsDiffer("1", "1.0"))
+	require.False(t, literalsDiffer("true", "1"))
+	require.False(t, literalsDiffer("1.1", "1.1000000001"))
+	require.True(t, literalsDiffer("1", "2"))
+	require.True(t, literalsDiffer("1", "one"))
+}
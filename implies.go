@@ -0,0 +1,173 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"reflect"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// Implication is the result of Implies
+type Implication int
+
+const (
+	// ImplicationUnknown means Implies could not decide
+	ImplicationUnknown Implication = iota
+	// ImplicationHolds means every datum matched by the first evaluator is
+	// matched by the second
+	ImplicationHolds
+	// ImplicationFails means a datum matched by the first evaluator but not
+	// by the second was found
+	ImplicationFails
+)
+
+func (i Implication) String() string {
+	switch i {
+	case ImplicationHolds:
+		return "Holds"
+	case ImplicationFails:
+		return "Fails"
+	default:
+		return "Unknown"
+	}
+}
+
+// Implies checks whether the expression of a implies the one of b, that is
+// whether any datum matched by a is also matched by b. This is decided by
+// looking for contradictions in "a and not b" with grammar.Analyze, so
+// ImplicationHolds is only returned for the cases it recognizes and assumes
+// the evaluation of b does not fail on data matched by a. ImplicationFails is
+// only returned once a counter-example was built and checked against both
+// evaluators; when neither can be established ImplicationUnknown is returned.
+//
+// Evaluators with different tag names, unknown values, local variables or
+// root options, or using hook functions, may resolve the same selector
+// differently and always give ImplicationUnknown.
+func Implies(a, b *Evaluator) (Implication, error) {
+	if a.tagName != b.tagName || a.valueTransformationHook != nil || b.valueTransformationHook != nil {
+		return ImplicationUnknown, nil
+	}
+	if (a.unknownVal == nil) != (b.unknownVal == nil) ||
+		(a.unknownVal != nil && !reflect.DeepEqual(*a.unknownVal, *b.unknownVal)) {
+		return ImplicationUnknown, nil
+	}
+	if !reflect.DeepEqual(a.createOpts.withLocalVariables, b.createOpts.withLocalVariables) ||
+		!reflect.DeepEqual(a.createOpts.withRoots, b.createOpts.withRoots) {
+		return ImplicationUnknown, nil
+	}
+
+	counter := &grammar.BinaryExpression{
+		Left:     a.ast,
+		Operator: grammar.BinaryOpAnd,
+		Right:    &grammar.UnaryExpression{Operator: grammar.UnaryOpNot, Operand: b.ast},
+	}
+	analysis, err := grammar.Analyze(counter, 0)
+	if err != nil {
+		return ImplicationUnknown, err
+	}
+	if analysis.Unsatisfiable {
+		return ImplicationHolds, nil
+	}
+
+	dnf, err := grammar.ToDNF(counter, 0)
+	if err != nil {
+		return ImplicationUnknown, err
+	}
+	for _, disjunct := range grammar.Operands(dnf, grammar.BinaryOpOr) {
+		datum, ok := buildCounterExample(grammar.Operands(disjunct, grammar.BinaryOpAnd))
+		if !ok {
+			continue
+		}
+		matchA, err := a.Evaluate(datum)
+		if err != nil || !matchA {
+			continue
+		}
+		matchB, err := b.Evaluate(datum)
+		if err == nil && !matchB {
+			return ImplicationFails, nil
+		}
+	}
+	return ImplicationUnknown, nil
+}
+
+// buildCounterExample tries to build a datum satisfying every match
+// expression in conjuncts. The result still has to be verified as it only
+// accounts for the simplest constraints.
+func buildCounterExample(conjuncts []grammar.Expression) (map[string]interface{}, bool) {
+	type constraint struct {
+		path  []string
+		value interface{}
+		set   bool
+	}
+	var constraints []*constraint
+	find := func(path []string) *constraint {
+		for _, c := range constraints {
+			if reflect.DeepEqual(c.path, path) {
+				return c
+			}
+		}
+		c := &constraint{path: path}
+		constraints = append(constraints, c)
+		return c
+	}
+
+	for _, conjunct := range conjuncts {
+		match, ok := conjunct.(*grammar.MatchExpression)
+		if !ok || len(match.Selector.Path) == 0 {
+			return nil, false
+		}
+		c := find(match.Selector.Path)
+
+		var value interface{}
+		switch match.Operator {
+		case grammar.MatchEqual, grammar.MatchIn:
+			// A string is equal to and contains itself
+			value = match.Value.Raw
+		case grammar.MatchIsOneOf:
+			value = match.Values[0].Raw
+		case grammar.MatchIsNotEmpty, grammar.MatchIsNotNil:
+			value = []interface{}{"bexpr-counter-example"}
+		default:
+			// Every other operator is satisfied by a missing key, which
+			// is the default
+			continue
+		}
+		if c.set && !reflect.DeepEqual(c.value, value) {
+			return nil, false
+		}
+		c.value, c.set = value, true
+	}
+
+	datum := make(map[string]interface{})
+	for _, c := range constraints {
+		parent := datum
+		for _, part := range c.path[:len(c.path)-1] {
+			child, ok := parent[part]
+			if !ok {
+				child = make(map[string]interface{})
+				parent[part] = child
+			}
+			m, ok := child.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			parent = m
+		}
+
+		last := c.path[len(c.path)-1]
+		if _, ok := parent[last]; ok {
+			// Another selector goes through this one
+			return nil, false
+		}
+		if c.set {
+			parent[last] = c.value
+		} else if len(c.path) == 1 {
+			// Missing keys are only tolerated below the top level, use a
+			// value no literal can be equal to instead
+			parent[last] = "\x00"
+		}
+	}
+	return datum, true
+}
@@ -0,0 +1,128 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplies(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		a, b     string
+		expected Implication
+	}
+
+	tests := map[string]testCase{
+		"identical": {
+			a:        `Env == "prod"`,
+			b:        `Env == "prod"`,
+			expected: ImplicationHolds,
+		},
+		"conjunction implies conjunct": {
+			a:        `Env == "prod" and Region == "eu"`,
+			b:        `Env == "prod"`,
+			expected: ImplicationHolds,
+		},
+		"conjunct does not imply conjunction": {
+			a:        `Env == "prod"`,
+			b:        `Env == "prod" and Region == "eu"`,
+			expected: ImplicationFails,
+		},
+		"disjunct implies disjunction": {
+			a:        `Env == "prod"`,
+			b:        `Env == "prod" or Env == "dev"`,
+			expected: ImplicationHolds,
+		},
+		"equality implies inequality": {
+			a:        `Env == "prod"`,
+			b:        `Env != "dev"`,
+			expected: ImplicationHolds,
+		},
+		"inequality does not imply equality": {
+			a:        `Env != "dev"`,
+			b:        `Env == "prod"`,
+			expected: ImplicationFails,
+		},
+		"nested selectors": {
+			a:        `Meta.Env == "prod"`,
+			b:        `Meta.Env == "prod" and Meta.Region != "us"`,
+			expected: ImplicationFails,
+		},
+		"containment implies not empty": {
+			a:        `"web" in Tags`,
+			b:        `Tags is not empty`,
+			expected: ImplicationHolds,
+		},
+		"different selectors": {
+			a:        `Env == "prod"`,
+			b:        `Region == "eu"`,
+			expected: ImplicationFails,
+		},
+		"regular expressions": {
+			a:        `Name matches "^web-"`,
+			b:        `Name matches "^web"`,
+			expected: ImplicationUnknown,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			a, err := CreateEvaluator(tcase.a)
+			require.NoError(t, err)
+			b, err := CreateEvaluator(tcase.b)
+			require.NoError(t, err)
+
+			result, err := Implies(a, b)
+			require.NoError(t, err)
+			require.Equal(t, tcase.expected, result, result.String())
+		})
+	}
+}
+
+func TestImplies_DifferentOptions(t *testing.T) {
+	t.Parallel()
+
+	a, err := CreateEvaluator(`Env == "prod"`)
+	require.NoError(t, err)
+	b, err := CreateEvaluator(`Env == "prod"`, WithTagName("json"))
+	require.NoError(t, err)
+
+	result, err := Implies(a, b)
+	require.NoError(t, err)
+	require.Equal(t, ImplicationUnknown, result)
+}
+
+func TestImplies_DifferentVariables(t *testing.T) {
+	t.Parallel()
+
+	// Env reads Prod.Env in b, so a datum with Env set to "prod" only
+	// matches a
+	a, err := CreateEvaluator(`Env == "prod"`)
+	require.NoError(t, err)
+	b, err := CreateEvaluator(`Env == "prod"`, WithLocalVariable("Env", []string{"Prod", "Env"}, nil))
+	require.NoError(t, err)
+
+	result, err := Implies(a, b)
+	require.NoError(t, err)
+	require.Equal(t, ImplicationUnknown, result)
+
+	c, err := CreateEvaluator(`Env == "prod"`, WithLocalVariable("Env", []string{"Prod", "Env"}, nil))
+	require.NoError(t, err)
+	result, err = Implies(b, c)
+	require.NoError(t, err)
+	require.Equal(t, ImplicationHolds, result)
+
+	d, err := CreateEvaluator(`Env == "prod"`, WithRootOptions("Env", WithTagName("json")))
+	require.NoError(t, err)
+	result, err = Implies(a, d)
+	require.NoError(t, err)
+	require.Equal(t, ImplicationUnknown, result)
+}
@@ -0,0 +1,490 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonlogic converts between JsonLogic rules (https://jsonlogic.com)
+// and bexpr expressions.
+//
+// The supported subset of JsonLogic maps onto bexpr as follows:
+//
+//	{"var": "a.b"}                        the selector a.b
+//	{"==": [{"var": "a"}, "x"]}           a == "x", "===" is also accepted
+//	{"!=": [{"var": "a"}, "x"]}           a != "x", "!==" is also accepted
+//	{"==": [{"var": "a"}, null]}          a is nil, "!=" gives a is not nil
+//	{"in": ["x", {"var": "a"}]}           "x" in a
+//	{"in": [{"var": "a"}, ["x", "y"]]}    a is one of ["x", "y"]
+//	{"!": {"var": "a"}}                   a is empty, "!!" gives a is not empty
+//	{"!": rule}, {"and": [...]}, {"or": [...]}
+//	{"some": [{"var": "a"}, rule]}        any a as v { rule }
+//	{"none": [{"var": "a"}, rule]}        not (any a as v { rule })
+//	{"all": [{"var": "a"}, rule]}         a is not empty and all a as v { rule }
+//	true, false                           constant expressions
+//
+// In the rule of some, all and none, variables are relative to the element
+// of the collection and {"var": ""} is the element itself; they are imported
+// as selectors on a local variable named after the nesting depth, v0 for the
+// outermost collection. The element is the only data such a rule can
+// reference so exporting a collection expression whose body references
+// anything else fails.
+//
+// JsonLogic's "all" is false for empty arrays while bexpr's is true, hence the
+// emptiness check added on import; on export "all" is written as "none" with
+// the negated rule.
+//
+// The conversion is approximate in two ways. JsonLogic compares values with
+// loose equality, so number-like and boolean-like bexpr literals are exported
+// as numbers and booleans to keep comparisons with typed values working, and
+// "!" tests truthiness, which agrees with "is empty" for strings, arrays and
+// missing values only. Every other JsonLogic operator, as well as "matches",
+// has no bexpr equivalent and is rejected with an error wrapping
+// ErrUnsupported.
+package jsonlogic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// ErrUnsupported is returned when a rule or an expression has no equivalent
+// in the other language.
+var ErrUnsupported = errors.New("unsupported by JsonLogic conversion")
+
+// Import parses the JsonLogic rule in data into a bexpr expression.
+func Import(data []byte) (grammar.Expression, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var rule interface{}
+	if err := dec.Decode(&rule); err != nil {
+		return nil, fmt.Errorf("failed to decode JsonLogic rule: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("failed to decode JsonLogic rule: unexpected data after the rule")
+	}
+	return importRule(rule, nil)
+}
+
+// Export converts expr into a JsonLogic rule encoded as JSON.
+func Export(expr grammar.Expression) ([]byte, error) {
+	rule, err := exportRule(expr, nil)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rule)
+}
+
+// operation splits a JsonLogic operation in its operator and arguments, an
+// argument that is not an array being a single argument.
+func operation(rule interface{}) (string, []interface{}, bool) {
+	m, ok := rule.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return "", nil, false
+	}
+	for op, args := range m {
+		if list, ok := args.([]interface{}); ok {
+			return op, list, true
+		}
+		return op, []interface{}{args}, true
+	}
+	return "", nil, false
+}
+
+func importRule(rule interface{}, scopes []string) (grammar.Expression, error) {
+	if b, ok := rule.(bool); ok {
+		return &grammar.ConstantExpression{Value: b}, nil
+	}
+
+	op, args, ok := operation(rule)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a condition", ErrUnsupported, encode(rule))
+	}
+
+	switch op {
+	case "and", "or":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%q requires at least one argument", op)
+		}
+		binOp := grammar.BinaryOpAnd
+		if op == "or" {
+			binOp = grammar.BinaryOpOr
+		}
+		operands := make([]grammar.Expression, 0, len(args))
+		for _, arg := range args {
+			expr, err := importRule(arg, scopes)
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, expr)
+		}
+		// Chain the operands to the right as the parser does
+		result := operands[len(operands)-1]
+		for i := len(operands) - 2; i >= 0; i-- {
+			result = &grammar.BinaryExpression{Left: operands[i], Operator: binOp, Right: result}
+		}
+		return result, nil
+	case "!", "!!":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%q requires one argument", op)
+		}
+		if sel, ok, err := importVar(args[0], scopes); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+			matchOp := grammar.MatchIsEmpty
+			if op == "!!" {
+				matchOp = grammar.MatchIsNotEmpty
+			}
+			return &grammar.MatchExpression{Selector: sel, Operator: matchOp}, nil
+		}
+		operand, err := importRule(args[0], scopes)
+		if err != nil {
+			return nil, err
+		}
+		if op == "!!" {
+			return operand, nil
+		}
+		return &grammar.UnaryExpression{Operator: grammar.UnaryOpNot, Operand: operand}, nil
+	case "==", "===", "!=", "!==":
+		return importEquality(op, args, scopes)
+	case "in":
+		return importIn(args, scopes)
+	case "some", "all", "none":
+		return importCollection(op, args, scopes)
+	default:
+		return nil, fmt.Errorf("%w: operator %q", ErrUnsupported, op)
+	}
+}
+
+// importVar converts a "var" operation into a selector, ok is false when arg
+// is not a "var" operation.
+func importVar(arg interface{}, scopes []string) (grammar.Selector, bool, error) {
+	op, args, ok := operation(arg)
+	if !ok || op != "var" {
+		return grammar.Selector{}, false, nil
+	}
+	if len(args) != 1 {
+		return grammar.Selector{}, true, fmt.Errorf("%w: default values of \"var\"", ErrUnsupported)
+	}
+
+	var name string
+	switch v := args[0].(type) {
+	case string:
+		name = v
+	case json.Number:
+		name = v.String()
+	default:
+		return grammar.Selector{}, true, fmt.Errorf("invalid \"var\" argument: %s", encode(args[0]))
+	}
+
+	var path []string
+	if len(scopes) > 0 {
+		path = append(path, scopes[len(scopes)-1])
+	}
+	if name != "" {
+		path = append(path, strings.Split(name, ".")...)
+	}
+	if len(path) == 0 {
+		return grammar.Selector{}, true, fmt.Errorf("%w: referencing the whole data", ErrUnsupported)
+	}
+	return grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: path}, true, nil
+}
+
+// importValue converts a JSON scalar into a match value, ok is false for null.
+func importValue(arg interface{}) (*grammar.MatchValue, bool, error) {
+	switch v := arg.(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		return &grammar.MatchValue{Raw: v}, true, nil
+	case json.Number:
+		return &grammar.MatchValue{Raw: v.String()}, true, nil
+	case bool:
+		return &grammar.MatchValue{Raw: strconv.FormatBool(v)}, true, nil
+	default:
+		return nil, false, fmt.Errorf("%w: comparing with %s", ErrUnsupported, encode(arg))
+	}
+}
+
+func importEquality(op string, args []interface{}, scopes []string) (grammar.Expression, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%q requires two arguments", op)
+	}
+
+	// The variable can be on either side
+	sel, ok, err := importVar(args[0], scopes)
+	other := args[1]
+	if !ok && err == nil {
+		sel, ok, err = importVar(args[1], scopes)
+		other = args[0]
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %q without a variable", ErrUnsupported, op)
+	}
+
+	value, ok, err := importValue(other)
+	if err != nil {
+		return nil, err
+	}
+	equal := op == "==" || op == "==="
+	switch {
+	case !ok && equal:
+		return &grammar.MatchExpression{Selector: sel, Operator: grammar.MatchIsNil}, nil
+	case !ok:
+		return &grammar.MatchExpression{Selector: sel, Operator: grammar.MatchIsNotNil}, nil
+	case equal:
+		return &grammar.MatchExpression{Selector: sel, Operator: grammar.MatchEqual, Value: value}, nil
+	default:
+		return &grammar.MatchExpression{Selector: sel, Operator: grammar.MatchNotEqual, Value: value}, nil
+	}
+}
+
+func importIn(args []interface{}, scopes []string) (grammar.Expression, error) {
+	if len(args) != 2 {
+		return nil, errors.New(`"in" requires two arguments`)
+	}
+
+	if sel, ok, err := importVar(args[1], scopes); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		value, ok, err := importValue(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: looking for null", ErrUnsupported)
+		}
+		return &grammar.MatchExpression{Selector: sel, Operator: grammar.MatchIn, Value: value}, nil
+	}
+
+	sel, ok, err := importVar(args[0], scopes)
+	if err != nil {
+		return nil, err
+	}
+	list, isList := args[1].([]interface{})
+	if !ok || !isList {
+		return nil, fmt.Errorf(`%w: "in" without a variable`, ErrUnsupported)
+	}
+	if len(list) == 0 {
+		return &grammar.ConstantExpression{Value: false}, nil
+	}
+	values := make([]*grammar.MatchValue, 0, len(list))
+	for _, item := range list {
+		value, ok, err := importValue(item)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: looking for null", ErrUnsupported)
+		}
+		values = append(values, value)
+	}
+	return &grammar.MatchExpression{Selector: sel, Operator: grammar.MatchIsOneOf, Values: values}, nil
+}
+
+func importCollection(op string, args []interface{}, scopes []string) (grammar.Expression, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%q requires two arguments", op)
+	}
+	sel, ok, err := importVar(args[0], scopes)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %q over %s", ErrUnsupported, op, encode(args[0]))
+	}
+
+	name := fmt.Sprintf("v%d", len(scopes))
+	inner, err := importRule(args[1], append(scopes, name))
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &grammar.CollectionExpression{
+		Op:       grammar.CollectionOpAny,
+		Selector: sel,
+		Inner:    inner,
+		NameBinding: grammar.CollectionNameBinding{
+			Mode:    grammar.CollectionBindDefault,
+			Default: name,
+		},
+	}
+	switch op {
+	case "none":
+		return &grammar.UnaryExpression{Operator: grammar.UnaryOpNot, Operand: expr}, nil
+	case "all":
+		expr.Op = grammar.CollectionOpAll
+		return &grammar.BinaryExpression{
+			Left:     &grammar.MatchExpression{Selector: sel, Operator: grammar.MatchIsNotEmpty},
+			Operator: grammar.BinaryOpAnd,
+			Right:    expr,
+		}, nil
+	default:
+		return expr, nil
+	}
+}
+
+func exportRule(expr grammar.Expression, scopes []string) (interface{}, error) {
+	switch node := expr.(type) {
+	case *grammar.ConstantExpression:
+		return node.Value, nil
+	case *grammar.UnaryExpression:
+		if node.Operator != grammar.UnaryOpNot {
+			return nil, fmt.Errorf("invalid unary operator: %s", node.Operator)
+		}
+		operand, err := exportRule(node.Operand, scopes)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"!": operand}, nil
+	case *grammar.BinaryExpression:
+		var op string
+		switch node.Operator {
+		case grammar.BinaryOpAnd:
+			op = "and"
+		case grammar.BinaryOpOr:
+			op = "or"
+		default:
+			return nil, fmt.Errorf("invalid binary operator: %s", node.Operator)
+		}
+		var args []interface{}
+		for _, operand := range grammar.Operands(node, node.Operator) {
+			arg, err := exportRule(operand, scopes)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		return map[string]interface{}{op: args}, nil
+	case *grammar.MatchExpression:
+		return exportMatch(node, scopes)
+	case *grammar.CollectionExpression:
+		return exportCollection(node, scopes)
+	default:
+		return nil, fmt.Errorf("invalid AST node: %T", expr)
+	}
+}
+
+// exportVar converts sel into a "var" operation, relative to the innermost
+// collection if any.
+func exportVar(sel grammar.Selector, scopes []string) (interface{}, error) {
+	path := sel.Path
+	if len(scopes) > 0 {
+		inner := scopes[len(scopes)-1]
+		if len(path) == 0 || path[0] != inner {
+			return nil, fmt.Errorf("%w: %q must reference the elements of the enclosing collection %q", ErrUnsupported, sel.String(), inner)
+		}
+		path = path[1:]
+	}
+	for _, part := range path {
+		if strings.Contains(part, ".") {
+			return nil, fmt.Errorf("%w: selector segment %q contains a dot", ErrUnsupported, part)
+		}
+	}
+	return map[string]interface{}{"var": strings.Join(path, ".")}, nil
+}
+
+// exportValue converts a literal into a JSON scalar, see the package
+// documentation.
+func exportValue(value *grammar.MatchValue) interface{} {
+	switch value.Raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if _, err := strconv.ParseFloat(value.Raw, 64); err == nil && json.Valid([]byte(value.Raw)) {
+		return json.Number(value.Raw)
+	}
+	return value.Raw
+}
+
+func exportMatch(expr *grammar.MatchExpression, scopes []string) (interface{}, error) {
+	v, err := exportVar(expr.Selector, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	not := func(rule interface{}) interface{} {
+		return map[string]interface{}{"!": rule}
+	}
+	switch expr.Operator {
+	case grammar.MatchEqual:
+		return map[string]interface{}{"==": []interface{}{v, exportValue(expr.Value)}}, nil
+	case grammar.MatchNotEqual:
+		return map[string]interface{}{"!=": []interface{}{v, exportValue(expr.Value)}}, nil
+	case grammar.MatchIn:
+		return map[string]interface{}{"in": []interface{}{exportValue(expr.Value), v}}, nil
+	case grammar.MatchNotIn:
+		return not(map[string]interface{}{"in": []interface{}{exportValue(expr.Value), v}}), nil
+	case grammar.MatchIsOneOf, grammar.MatchIsNotOneOf:
+		values := make([]interface{}, 0, len(expr.Values))
+		for _, value := range expr.Values {
+			values = append(values, exportValue(value))
+		}
+		rule := map[string]interface{}{"in": []interface{}{v, values}}
+		if expr.Operator == grammar.MatchIsNotOneOf {
+			return not(rule), nil
+		}
+		return rule, nil
+	case grammar.MatchIsEmpty:
+		return map[string]interface{}{"!": v}, nil
+	case grammar.MatchIsNotEmpty:
+		return map[string]interface{}{"!!": v}, nil
+	case grammar.MatchIsNil:
+		return map[string]interface{}{"==": []interface{}{v, nil}}, nil
+	case grammar.MatchIsNotNil:
+		return map[string]interface{}{"!=": []interface{}{v, nil}}, nil
+	case grammar.MatchMatches, grammar.MatchNotMatches:
+		return nil, fmt.Errorf("%w: regular expression match on %s", ErrUnsupported, expr.Selector)
+	default:
+		return nil, fmt.Errorf("invalid match operation: %d", expr.Operator)
+	}
+}
+
+func exportCollection(expr *grammar.CollectionExpression, scopes []string) (interface{}, error) {
+	var name string
+	switch expr.NameBinding.Mode {
+	case grammar.CollectionBindDefault:
+		name = expr.NameBinding.Default
+	case grammar.CollectionBindValue:
+		name = expr.NameBinding.Value
+	default:
+		return nil, fmt.Errorf("%w: binding the index of %s", ErrUnsupported, expr.Selector)
+	}
+
+	v, err := exportVar(expr.Selector, scopes)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := exportRule(expr.Inner, append(scopes, name))
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.Op {
+	case grammar.CollectionOpAny:
+		return map[string]interface{}{"some": []interface{}{v, inner}}, nil
+	case grammar.CollectionOpAll:
+		// JsonLogic's all is false for empty arrays
+		return map[string]interface{}{"none": []interface{}{v, map[string]interface{}{"!": inner}}}, nil
+	default:
+		return nil, fmt.Errorf("invalid collection operator: %s", expr.Op)
+	}
+}
+
+func encode(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,233 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonlogic
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, input string) grammar.Expression {
+	t.Helper()
+	expr, err := grammar.Parse("", []byte(input))
+	require.NoError(t, err)
+	return expr.(grammar.Expression)
+}
+
+func dump(expr grammar.Expression) string {
+	var buf bytes.Buffer
+	expr.ExpressionDump(&buf, "   ", 0)
+	return buf.String()
+}
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		rule     string
+		expected string
+		err      string
+	}
+
+	tests := map[string]testCase{
+		"equal": {
+			rule:     `{"==": [{"var": "Meta.env"}, "prod"]}`,
+			expected: `Meta.env == "prod"`,
+		},
+		"strict equal with literal first": {
+			rule:     `{"===": [8080, {"var": "Port"}]}`,
+			expected: `Port == 8080`,
+		},
+		"not equal": {
+			rule:     `{"!=": [{"var": "Enabled"}, true]}`,
+			expected: `Enabled != true`,
+		},
+		"nil": {
+			rule:     `{"and": [{"==": [{"var": "A"}, null]}, {"!==": [{"var": "B"}, null]}]}`,
+			expected: `A is nil and B is not nil`,
+		},
+		"in": {
+			rule:     `{"in": ["prod", {"var": "Tags"}]}`,
+			expected: `"prod" in Tags`,
+		},
+		"empty": {
+			rule:     `{"or": [{"!": {"var": "A"}}, {"!!": [{"var": "B"}]}]}`,
+			expected: `A is empty or B is not empty`,
+		},
+		"not": {
+			rule:     `{"!": {"in": ["prod", {"var": "Tags"}]}}`,
+			expected: `not "prod" in Tags`,
+		},
+		"and or": {
+			rule:     `{"and": [{"==": [{"var": "A"}, "1"]}, {"==": [{"var": "B"}, "2"]}, {"or": [{"==": [{"var": "C"}, "3"]}, {"==": [{"var": "D"}, "4"]}]}]}`,
+			expected: `A == 1 and B == 2 and (C == 3 or D == 4)`,
+		},
+		"some": {
+			rule:     `{"some": [{"var": "Checks"}, {"==": [{"var": "Status"}, "passing"]}]}`,
+			expected: `any Checks as v0 { v0.Status == "passing" }`,
+		},
+		"none": {
+			rule:     `{"none": [{"var": "Tags"}, {"==": [{"var": ""}, "deprecated"]}]}`,
+			expected: `not (any Tags as v0 { v0 == "deprecated" })`,
+		},
+		"all": {
+			rule:     `{"all": [{"var": "Checks"}, {"some": [{"var": "Notes"}, {"==": [{"var": ""}, "ok"]}]}]}`,
+			expected: `Checks is not empty and (all Checks as v0 { any v0.Notes as v1 { v1 == "ok" } })`,
+		},
+		"constant": {
+			rule:     `true`,
+			expected: ``,
+		},
+		"unsupported operator": {
+			rule: `{">": [{"var": "Port"}, 1024]}`,
+			err:  `unsupported by JsonLogic conversion: operator ">"`,
+		},
+		"var default": {
+			rule: `{"==": [{"var": ["Port", 80]}, 8080]}`,
+			err:  `unsupported by JsonLogic conversion: default values of "var"`,
+		},
+		"two literals": {
+			rule: `{"==": [1, 1]}`,
+			err:  `unsupported by JsonLogic conversion: "==" without a variable`,
+		},
+		"not a condition": {
+			rule: `"prod"`,
+			err:  `unsupported by JsonLogic conversion: "prod" is not a condition`,
+		},
+		"invalid json": {
+			rule: `{"==": `,
+			err:  `failed to decode JsonLogic rule: unexpected EOF`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := Import([]byte(tcase.rule))
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			if tcase.expected == "" {
+				require.Equal(t, &grammar.ConstantExpression{Value: true}, expr)
+				return
+			}
+			require.Equal(t, dump(parse(t, tcase.expected)), dump(expr))
+		})
+	}
+}
+
+func TestImport_OneOf(t *testing.T) {
+	t.Parallel()
+
+	expr, err := Import([]byte(`{"in": [{"var": "Env"}, ["dev", "test"]]}`))
+	require.NoError(t, err)
+	require.Equal(t, dump(grammar.Simplify(parse(t, `Env == dev or Env == test`))), dump(expr))
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		input    string
+		expected string
+		err      string
+		simplify bool
+	}
+
+	tests := map[string]testCase{
+		"equal": {
+			input:    `Name == "web" and Port == 8080 and Enabled != true`,
+			expected: `{"and":[{"==":[{"var":"Name"},"web"]},{"==":[{"var":"Port"},8080]},{"!=":[{"var":"Enabled"},true]}]}`,
+		},
+		"in": {
+			input:    `"prod" in Tags or Tags not contains "dev"`,
+			expected: `{"or":[{"in":["prod",{"var":"Tags"}]},{"!":{"in":["dev",{"var":"Tags"}]}}]}`,
+		},
+		"empty and nil": {
+			input:    `A is empty and B is not empty and C is nil and D is not nil`,
+			expected: `{"and":[{"!":{"var":"A"}},{"!!":{"var":"B"}},{"==":[{"var":"C"},null]},{"!=":[{"var":"D"},null]}]}`,
+		},
+		"one of": {
+			input:    `Env != dev and Env != test`,
+			expected: `{"!":{"in":[{"var":"Env"},["dev","test"]]}}`,
+			simplify: true,
+		},
+		"any": {
+			input:    `any Checks as c { c.Status == "passing" }`,
+			expected: `{"some":[{"var":"Checks"},{"==":[{"var":"Status"},"passing"]}]}`,
+		},
+		"all": {
+			input:    `all Tags as _, t { t != "deprecated" }`,
+			expected: `{"none":[{"var":"Tags"},{"!":{"!=":[{"var":""},"deprecated"]}}]}`,
+		},
+		"matches": {
+			input: `Name matches "^web"`,
+			err:   "unsupported by JsonLogic conversion: regular expression match on Name",
+		},
+		"outer reference": {
+			input: `any Checks as c { Name == "web" }`,
+			err:   `unsupported by JsonLogic conversion: "Name" must reference the elements of the enclosing collection "c"`,
+		},
+		"index binding": {
+			input: `any Checks as i, _ { i == 0 }`,
+			err:   "unsupported by JsonLogic conversion: binding the index of Checks",
+		},
+		"dotted segment": {
+			input: `Labels["app.kubernetes.io/name"] == "web"`,
+			err:   `unsupported by JsonLogic conversion: selector segment "app.kubernetes.io/name" contains a dot`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr := parse(t, tcase.input)
+			if tcase.simplify {
+				expr = grammar.Simplify(expr)
+			}
+			rule, err := Export(expr)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				require.True(t, errors.Is(err, ErrUnsupported))
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tcase.expected, string(rule))
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	inputs := map[string]string{
+		"match":      `Name == "web" and (Port != 8080 or "x" in Tags)`,
+		"emptiness":  `A is empty or B is not nil`,
+		"collection": `any Checks as v0 { v0.Status == "passing" and (any v0.Notes as v1 { v1 == "ok" }) }`,
+	}
+
+	for name, input := range inputs {
+		input := input
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr := parse(t, input)
+			rule, err := Export(expr)
+			require.NoError(t, err)
+			imported, err := Import(rule)
+			require.NoError(t, err)
+			require.Equal(t, dump(expr), dump(imported))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// Result is the result of a three-valued evaluation, see
+// Evaluator.EvaluateThreeValued
+type Result int
+
+const (
+	ResultFalse Result = iota
+	ResultTrue
+	// ResultUnknown is the result of expressions that depend on a missing
+	// value
+	ResultUnknown
+)
+
+func (r Result) String() string {
+	switch r {
+	case ResultFalse:
+		return "false"
+	case ResultTrue:
+		return "true"
+	case ResultUnknown:
+		return "unknown"
+	default:
+		return "invalid"
+	}
+}
+
+func resultOf(b bool) Result {
+	if b {
+		return ResultTrue
+	}
+	return ResultFalse
+}
+
+func (r Result) not() Result {
+	switch r {
+	case ResultFalse:
+		return ResultTrue
+	case ResultTrue:
+		return ResultFalse
+	default:
+		return r
+	}
+}
+
+// EvaluateThreeValued evaluates the expression against datum using Kleene
+// logic. Instead of using the NotPresentDisposition of their operator, the
+// match and collection expressions whose selector references a missing map
+// key are unknown, and so is `not` of an unknown expression. An `and` is false
+// as soon as one of its operands is, unknown if one of them is and true
+// otherwise, and `or` is the other way around. Likewise `any` is true as soon
+// as an element matches and `all` false as soon as one does not, their result
+// being unknown when no element decided it but one of them was unknown.
+//
+// WithUnknownValue is ignored in this mode, missing keys always being unknown,
+// including the keys missing from the datum itself when it is a map. The other
+// options apply like in Evaluate.
+func (eval *Evaluator) EvaluateThreeValued(datum interface{}) (Result, error) {
+	opts := *eval.runOptions(nil)
+	opts.withUnknown = nil
+	opts.withMissingTopKeys = true
+	result, err := evaluateThreeValued(eval.root, datum, &opts)
+	if err != nil {
+		return ResultFalse, err
+	}
+	return result, nil
+}
+
+func evaluateThreeValued(n *compiledNode, datum interface{}, opts *options) (Result, error) {
+	if err := opts.withBudget.visit(); err != nil {
+		return ResultFalse, err
+	}
+
+	switch node := n.expression.(type) {
+	case *grammar.UnaryExpression:
+		result, err := evaluateThreeValued(n.children[0], datum, opts)
+		return result.not(), err
+	case *grammar.BinaryExpression:
+		// An operand equal to decider decides the result of the expression
+		decider := resultOf(node.Operator == grammar.BinaryOpOr)
+		left, err := evaluateThreeValued(n.children[0], datum, opts)
+		if err != nil || left == decider {
+			return left, err
+		}
+		right, err := evaluateThreeValued(n.children[1], datum, opts)
+		if err != nil || right == decider {
+			return right, err
+		}
+		if left == ResultUnknown {
+			return ResultUnknown, nil
+		}
+		return right, nil
+	case *grammar.MatchExpression:
+		value, present, err := n.match.lookup(datum, opts)
+		if err != nil {
+			return ResultFalse, err
+		}
+		if !present {
+			return ResultUnknown, nil
+		}
+		match, err := n.match.matchValue(value, opts)
+		return resultOf(match), err
+	case *grammar.CollectionExpression:
+		return evaluateThreeValuedCollection(node, n.children[0], datum, opts)
+	case *grammar.ConstantExpression:
+		return resultOf(node.Value), nil
+	default:
+		return ResultFalse, fmt.Errorf("invalid AST node")
+	}
+}
+
+func evaluateThreeValuedCollection(node *grammar.CollectionExpression, body *compiledNode, datum interface{}, opts *options) (Result, error) {
+	// An element equal to decider decides the result of the collection
+	decider := resultOf(node.Op == grammar.CollectionOpAny)
+	result := decider.not()
+
+	present, err := iterateCollection(node, datum, opts, func(innerOpts *options, _ []reflect.Value, _ int) (bool, error) {
+		inner, err := evaluateThreeValued(body, datum, innerOpts)
+		if err != nil {
+			return true, err
+		}
+		switch inner {
+		case decider:
+			result = decider
+			return true, nil
+		case ResultUnknown:
+			result = ResultUnknown
+		}
+		return false, nil
+	})
+	switch {
+	case err != nil:
+		return ResultFalse, err
+	case !present:
+		return ResultUnknown, nil
+	}
+	return result, nil
+}
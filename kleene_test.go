@@ -0,0 +1,135 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvaluateThreeValued_Evaluate checks the known results of three-valued
+// evaluations are the results of Evaluate
+func TestEvaluateThreeValued_Evaluate(t *testing.T) {
+	t.Parallel()
+	for name, tcase := range evaluateTests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for i, expTest := range tcase.expressions {
+				eval, err := CreateEvaluator(expTest.expression, WithHookFn(expTest.hook))
+				require.NoError(t, err)
+
+				match, err := eval.Evaluate(tcase.value)
+				result, threeValuedErr := eval.EvaluateThreeValued(tcase.value)
+				if err != nil || threeValuedErr != nil || result == ResultUnknown {
+					continue
+				}
+				require.Equal(t, resultOf(match), result, "#%d - %s", i, expTest.expression)
+			}
+		})
+	}
+}
+
+func TestEvaluateThreeValued(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		expression string
+		result     Result
+		err        string
+	}
+
+	datum := map[string]interface{}{
+		"Name":   "web",
+		"Labels": map[string]string{"env": "prod"},
+		"Tags":   []string{"a", "b"},
+		"Items": []map[string]interface{}{
+			{"Size": 1},
+			{"Size": 2, "Color": "red"},
+		},
+	}
+
+	tests := map[string]testCase{
+		"known":                {expression: `Name == "web"`, result: ResultTrue},
+		"missing equal":        {expression: `Labels.team == ""`, result: ResultUnknown},
+		"missing not equal":    {expression: `Labels.team != ""`, result: ResultUnknown},
+		"missing in":           {expression: `"a" in Labels.team`, result: ResultUnknown},
+		"not unknown":          {expression: `not Labels.team == "core"`, result: ResultUnknown},
+		"and false":            {expression: `Labels.team == "core" and Name == "db"`, result: ResultFalse},
+		"and unknown":          {expression: `Name == "web" and Labels.team == "core"`, result: ResultUnknown},
+		"or true":              {expression: `Labels.team == "core" or Name == "web"`, result: ResultTrue},
+		"or unknown":           {expression: `Labels.team == "core" or Name == "db"`, result: ResultUnknown},
+		"missing collection":   {expression: `all Labels.team as t { t == "a" }`, result: ResultUnknown},
+		"any true":             {expression: `any Items as item { item.Color == "red" }`, result: ResultTrue},
+		"any unknown":          {expression: `any Items as item { item.Color == "blue" }`, result: ResultUnknown},
+		"any false":            {expression: `any Tags as tag { tag == "c" }`, result: ResultFalse},
+		"all false":            {expression: `all Items as item { item.Color == "blue" }`, result: ResultFalse},
+		"all unknown":          {expression: `all Items as item { item.Color == "red" }`, result: ResultUnknown},
+		"all true":             {expression: `all Items as item { item.Size != 3 }`, result: ResultTrue},
+		"unknown value unused": {expression: `Labels.team == "none"`, result: ResultUnknown},
+		"missing top key":      {expression: `Team == "core"`, result: ResultUnknown},
+		"or missing top key":   {expression: `Team == "core" or Name == "web"`, result: ResultTrue},
+		"error": {
+			expression: `Labels.team == "core" or Name.first == "a"`,
+			err:        `error finding value in datum: /Name/first: at part 1, invalid value kind: string`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			eval, err := CreateEvaluator(tcase.expression, WithUnknownValue("none"))
+			require.NoError(t, err)
+
+			result, err := eval.EvaluateThreeValued(datum)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tcase.result, result)
+		})
+	}
+}
+
+func TestEvaluateThreeValued_FlatMap(t *testing.T) {
+	t.Parallel()
+
+	datum := map[string]any{"a": 1}
+
+	eval, err := CreateEvaluator(`b == 1 or a == 1`)
+	require.NoError(t, err)
+	result, err := eval.EvaluateThreeValued(datum)
+	require.NoError(t, err)
+	require.Equal(t, ResultTrue, result)
+
+	// Evaluate still reports the missing key
+	_, err = eval.Evaluate(datum)
+	require.EqualError(t, err, `error finding value in datum: /b at part 0: couldn't find key "b"`)
+
+	eval, err = CreateEvaluator(`b == 1 and a == 1`)
+	require.NoError(t, err)
+	result, err = eval.EvaluateThreeValued(datum)
+	require.NoError(t, err)
+	require.Equal(t, ResultUnknown, result)
+
+	// Missing struct fields are not missing data
+	type flat struct{ A int }
+	eval, err = CreateEvaluator(`B == 1 or A == 1`)
+	require.NoError(t, err)
+	_, err = eval.EvaluateThreeValued(flat{A: 1})
+	require.Error(t, err)
+}
+
+func TestResult_String(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "false", ResultFalse.String())
+	require.Equal(t, "true", ResultTrue.String())
+	require.Equal(t, "unknown", ResultUnknown.String())
+}
@@ -0,0 +1,452 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package kubeselector parses Kubernetes label and field selectors into bexpr
+// expressions so they can be evaluated with bexpr.CreateEvaluatorFromAST and
+// bexpr.CreateFilterFromAST.
+//
+// Label selectors are comma separated requirements on the keys of a map,
+// which is the "Labels" field by default:
+//
+//	env in (prod,staging),tier!=frontend,!canary
+//
+// is equivalent to
+//
+//	Labels.env is one of ["prod", "staging"] and Labels.tier != "frontend" and "canary" not in Labels
+//
+// As in Kubernetes, "!=" and "notin" match objects missing the label. Field
+// selectors only support "=", "==" and "!=" and their keys are dotted paths,
+// looked up below a configurable prefix.
+package kubeselector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// ErrUnsupported is returned for the selector operators bexpr cannot express,
+// the "<" and ">" label selector operators.
+var ErrUnsupported = errors.New("unsupported selector operator")
+
+// DefaultLabels is the selector of the labels map used when ParseLabels is
+// given none.
+var DefaultLabels = []string{"Labels"}
+
+// ParseLabels parses a Kubernetes label selector into a bexpr expression
+// over the map at the labels path, DefaultLabels when empty. The empty
+// selector matches everything.
+func ParseLabels(selector string, labels ...string) (grammar.Expression, error) {
+	if len(labels) == 0 {
+		labels = DefaultLabels
+	}
+	p := parser{lexer: lexer{input: selector}, labels: labels}
+	return p.parse(p.labelRequirement)
+}
+
+// ParseFields parses a Kubernetes field selector into a bexpr expression.
+// The keys of the selector are split on dots and appended to prefix, so
+// "metadata.name=web" becomes `metadata.name == "web"` without prefix. The
+// empty selector matches everything.
+func ParseFields(selector string, prefix ...string) (grammar.Expression, error) {
+	p := parser{lexer: lexer{input: selector, fields: true}, labels: prefix}
+	return p.parse(p.fieldRequirement)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenComma
+	tokenEqual
+	tokenDoubleEqual
+	tokenNotEqual
+	tokenNot
+	tokenIn
+	tokenNotIn
+	tokenGreater
+	tokenLess
+	tokenOpenParen
+	tokenCloseParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of selector"
+	}
+	return fmt.Sprintf("%q at position %d", t.value, t.pos)
+}
+
+// lexer splits selectors into tokens. In field selectors, values can contain
+// any character and commas, equal signs and backslashes are escaped with a
+// backslash.
+type lexer struct {
+	input  string
+	pos    int
+	fields bool
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+
+	two := l.input[l.pos:]
+	switch {
+	case strings.HasPrefix(two, "=="):
+		l.pos += 2
+		return token{kind: tokenDoubleEqual, value: "==", pos: start}, nil
+	case strings.HasPrefix(two, "!="):
+		l.pos += 2
+		return token{kind: tokenNotEqual, value: "!=", pos: start}, nil
+	}
+
+	var kind tokenKind
+	switch l.input[l.pos] {
+	case ',':
+		kind = tokenComma
+	case '=':
+		kind = tokenEqual
+	case '!':
+		kind = tokenNot
+	case '>':
+		kind = tokenGreater
+	case '<':
+		kind = tokenLess
+	case '(':
+		kind = tokenOpenParen
+	case ')':
+		kind = tokenCloseParen
+	default:
+		return l.identifier()
+	}
+	l.pos++
+	return token{kind: kind, value: l.input[start:l.pos], pos: start}, nil
+}
+
+func (l *lexer) identifier() (token, error) {
+	start := l.pos
+	var value strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if l.fields && c == '\\' {
+			if l.pos+1 >= len(l.input) || !strings.ContainsRune(`\,=`, rune(l.input[l.pos+1])) {
+				return token{}, fmt.Errorf("invalid escape sequence at position %d", l.pos)
+			}
+			value.WriteByte(l.input[l.pos+1])
+			l.pos += 2
+			continue
+		}
+		if isSpace(c) || strings.IndexByte(",=!<>()", c) >= 0 {
+			break
+		}
+		value.WriteByte(c)
+		l.pos++
+	}
+
+	t := token{kind: tokenIdentifier, value: value.String(), pos: start}
+	if !l.fields {
+		switch t.value {
+		case "in":
+			t.kind = tokenIn
+		case "notin":
+			t.kind = tokenNotIn
+		}
+	}
+	return t, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+type parser struct {
+	lexer  lexer
+	labels []string
+	peeked *token
+}
+
+func (p *parser) peek() (token, error) {
+	if p.peeked == nil {
+		t, err := p.lexer.next()
+		if err != nil {
+			return token{}, err
+		}
+		p.peeked = &t
+	}
+	return *p.peeked, nil
+}
+
+func (p *parser) next() (token, error) {
+	t, err := p.peek()
+	p.peeked = nil
+	return t, err
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t, err := p.next()
+	if err != nil {
+		return token{}, err
+	}
+	if t.kind != kind {
+		return token{}, fmt.Errorf("expected %s, found %s", what, t)
+	}
+	return t, nil
+}
+
+// value reads an optional value, values can be empty in Kubernetes selectors
+func (p *parser) value() (string, error) {
+	t, err := p.peek()
+	if err != nil {
+		return "", err
+	}
+	if t.kind != tokenIdentifier {
+		return "", nil
+	}
+	p.next()
+	return t.value, nil
+}
+
+func (p *parser) parse(requirement func() (grammar.Expression, error)) (grammar.Expression, error) {
+	var exprs []grammar.Expression
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenEOF && len(exprs) == 0 {
+			return &grammar.ConstantExpression{Value: true}, nil
+		}
+
+		expr, err := requirement()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+
+		t, err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenEOF {
+			break
+		}
+		if t.kind != tokenComma {
+			return nil, fmt.Errorf("expected \",\" or end of selector, found %s", t)
+		}
+	}
+
+	// Chain the requirements to the right as the bexpr parser does
+	result := exprs[len(exprs)-1]
+	for i := len(exprs) - 2; i >= 0; i-- {
+		result = &grammar.BinaryExpression{Left: exprs[i], Operator: grammar.BinaryOpAnd, Right: result}
+	}
+	return result, nil
+}
+
+func (p *parser) selector(path ...string) grammar.Selector {
+	full := make([]string, 0, len(p.labels)+len(path))
+	full = append(full, p.labels...)
+	full = append(full, path...)
+	return grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: full}
+}
+
+func (p *parser) labelRequirement() (grammar.Expression, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.kind == tokenNot {
+		key, err := p.expect(tokenIdentifier, "label key")
+		if err != nil {
+			return nil, err
+		}
+		if err := validateKey(key.value); err != nil {
+			return nil, err
+		}
+		return &grammar.MatchExpression{
+			Selector: p.selector(),
+			Operator: grammar.MatchNotIn,
+			Value:    &grammar.MatchValue{Raw: key.value},
+		}, nil
+	}
+	if t.kind != tokenIdentifier {
+		return nil, fmt.Errorf("expected label key, found %s", t)
+	}
+	key := t.value
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	op, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	switch op.kind {
+	case tokenEOF, tokenComma:
+		return &grammar.MatchExpression{
+			Selector: p.selector(),
+			Operator: grammar.MatchIn,
+			Value:    &grammar.MatchValue{Raw: key},
+		}, nil
+	case tokenEqual, tokenDoubleEqual, tokenNotEqual:
+		p.next()
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if err := validateValue(value); err != nil {
+			return nil, err
+		}
+		matchOp := grammar.MatchEqual
+		if op.kind == tokenNotEqual {
+			matchOp = grammar.MatchNotEqual
+		}
+		return &grammar.MatchExpression{
+			Selector: p.selector(key),
+			Operator: matchOp,
+			Value:    &grammar.MatchValue{Raw: value},
+		}, nil
+	case tokenIn, tokenNotIn:
+		p.next()
+		values, err := p.valueSet()
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 1 {
+			matchOp := grammar.MatchEqual
+			if op.kind == tokenNotIn {
+				matchOp = grammar.MatchNotEqual
+			}
+			return &grammar.MatchExpression{Selector: p.selector(key), Operator: matchOp, Value: values[0]}, nil
+		}
+		matchOp := grammar.MatchIsOneOf
+		if op.kind == tokenNotIn {
+			matchOp = grammar.MatchIsNotOneOf
+		}
+		return &grammar.MatchExpression{
+			Selector: p.selector(key),
+			Operator: matchOp,
+			Values:   values,
+		}, nil
+	case tokenGreater, tokenLess:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, op)
+	default:
+		return nil, fmt.Errorf("expected operator, found %s", op)
+	}
+}
+
+func (p *parser) valueSet() ([]*grammar.MatchValue, error) {
+	if _, err := p.expect(tokenOpenParen, `"("`); err != nil {
+		return nil, err
+	}
+
+	var values []*grammar.MatchValue
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if err := validateValue(value); err != nil {
+			return nil, err
+		}
+		values = append(values, &grammar.MatchValue{Raw: value})
+
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenCloseParen {
+			return values, nil
+		}
+		if t.kind != tokenComma {
+			return nil, fmt.Errorf("expected \",\" or \")\", found %s", t)
+		}
+	}
+}
+
+func (p *parser) fieldRequirement() (grammar.Expression, error) {
+	key, err := p.expect(tokenIdentifier, "field")
+	if err != nil {
+		return nil, err
+	}
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var matchOp grammar.MatchOperator
+	switch op.kind {
+	case tokenEqual, tokenDoubleEqual:
+		matchOp = grammar.MatchEqual
+	case tokenNotEqual:
+		matchOp = grammar.MatchNotEqual
+	default:
+		return nil, fmt.Errorf("expected \"=\", \"==\" or \"!=\", found %s", op)
+	}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	return &grammar.MatchExpression{
+		Selector: p.selector(strings.Split(key.value, ".")...),
+		Operator: matchOp,
+		Value:    &grammar.MatchValue{Raw: value},
+	}, nil
+}
+
+// validateKey checks label keys: an optional DNS subdomain prefix followed by
+// a slash and a name of at most 63 characters.
+func validateKey(key string) error {
+	name := key
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if prefix == "" || len(prefix) > 253 || !validChars(prefix, "-.") {
+			return fmt.Errorf("invalid label key %q: invalid prefix", key)
+		}
+	}
+	if name == "" || len(name) > 63 || !validChars(name, "-_.") || !isAlphanumeric(name[0]) || !isAlphanumeric(name[len(name)-1]) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
+
+// validateValue checks label values: empty or at most 63 characters starting
+// and ending with an alphanumeric character.
+func validateValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > 63 || !validChars(value, "-_.") || !isAlphanumeric(value[0]) || !isAlphanumeric(value[len(value)-1]) {
+		return fmt.Errorf("invalid label value %q", value)
+	}
+	return nil
+}
+
+func validChars(s string, extra string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isAlphanumeric(s[i]) && strings.IndexByte(extra, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,240 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package kubeselector
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-bexpr"
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+func dump(expr grammar.Expression) string {
+	var buf bytes.Buffer
+	expr.ExpressionDump(&buf, "   ", 0)
+	return buf.String()
+}
+
+func parse(t *testing.T, input string) grammar.Expression {
+	t.Helper()
+	expr, err := grammar.Parse("", []byte(input))
+	require.NoError(t, err)
+	// Simplify merges the equality checks into "is one of"
+	return grammar.Simplify(expr.(grammar.Expression))
+}
+
+func TestParseLabels(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		selector string
+		labels   []string
+		expected string
+		ast      grammar.Expression
+		err      string
+	}
+
+	tests := map[string]testCase{
+		"equal": {
+			selector: "env=prod",
+			expected: `Labels.env == "prod"`,
+		},
+		"double equal": {
+			selector: "env == prod",
+			expected: `Labels.env == "prod"`,
+		},
+		"empty value": {
+			selector: "env=",
+			expected: `Labels.env == ""`,
+		},
+		"not equal": {
+			selector: "tier!=frontend",
+			expected: `Labels.tier != "frontend"`,
+		},
+		"exists": {
+			selector: "canary",
+			expected: `"canary" in Labels`,
+		},
+		"does not exist": {
+			selector: "!canary",
+			expected: `"canary" not in Labels`,
+		},
+		"in": {
+			selector: "env in (prod, staging)",
+			expected: `Labels.env == prod or Labels.env == staging`,
+		},
+		"notin": {
+			selector: "env notin (dev)",
+			expected: `Labels.env != dev`,
+		},
+		"combined": {
+			selector: "env in (prod,staging),tier!=frontend,!canary",
+			expected: `(Labels.env == prod or Labels.env == staging) and Labels.tier != frontend and "canary" not in Labels`,
+		},
+		"prefixed key": {
+			selector: "app.kubernetes.io/name=web",
+			labels:   []string{"Metadata", "Labels"},
+			ast: &grammar.MatchExpression{
+				Selector: grammar.Selector{
+					Type: grammar.SelectorTypeBexpr,
+					Path: []string{"Metadata", "Labels", "app.kubernetes.io/name"},
+				},
+				Operator: grammar.MatchEqual,
+				Value:    &grammar.MatchValue{Raw: "web"},
+			},
+		},
+		"unsupported operator": {
+			selector: "replicas>3",
+			err:      `unsupported selector operator: ">" at position 8`,
+		},
+		"invalid key": {
+			selector: "-env=prod",
+			err:      `invalid label key "-env"`,
+		},
+		"invalid value": {
+			selector: "env=prod-",
+			err:      `invalid label value "prod-"`,
+		},
+		"missing parenthesis": {
+			selector: "env in (prod",
+			err:      `expected "," or ")", found end of selector`,
+		},
+		"trailing comma": {
+			selector: "env=prod,",
+			err:      `expected label key, found end of selector`,
+		},
+		"missing comma": {
+			selector: "env=prod tier=web",
+			err:      `expected "," or end of selector, found "tier" at position 9`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := ParseLabels(tcase.selector, tcase.labels...)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			if tcase.ast != nil {
+				require.Equal(t, tcase.ast, expr)
+				return
+			}
+			require.Equal(t, dump(parse(t, tcase.expected)), dump(expr))
+		})
+	}
+}
+
+func TestParseLabels_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseLabels("replicas<3")
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestParseLabels_Empty(t *testing.T) {
+	t.Parallel()
+
+	expr, err := ParseLabels("  ")
+	require.NoError(t, err)
+	require.Equal(t, &grammar.ConstantExpression{Value: true}, expr)
+}
+
+func TestParseFields(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		selector string
+		prefix   []string
+		expected string
+		err      string
+	}
+
+	tests := map[string]testCase{
+		"equal": {
+			selector: "metadata.name=web",
+			expected: `metadata.name == web`,
+		},
+		"prefix": {
+			selector: "status.phase!=Running,spec.nodeName==node-1",
+			prefix:   []string{"Object"},
+			expected: `Object.status.phase != Running and Object.spec.nodeName == "node-1"`,
+		},
+		"escaped value": {
+			selector: `metadata.annotations.note=a\,b\=c\\d`,
+			expected: `metadata.annotations.note == "a,b=c\\d"`,
+		},
+		"invalid escape": {
+			selector: `metadata.name=a\b`,
+			err:      `invalid escape sequence at position 15`,
+		},
+		"set operator": {
+			selector: "metadata.name in (a)",
+			err:      `expected "=", "==" or "!=", found "in" at position 14`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := ParseFields(tcase.selector, tcase.prefix...)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, dump(parse(t, tcase.expected)), dump(expr))
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	type pod struct {
+		Name   string
+		Labels map[string]string
+	}
+
+	pods := []pod{
+		{Name: "a", Labels: map[string]string{"env": "prod", "tier": "backend"}},
+		{Name: "b", Labels: map[string]string{"env": "staging", "tier": "frontend"}},
+		{Name: "c", Labels: map[string]string{"env": "prod", "canary": "true"}},
+		{Name: "d", Labels: map[string]string{"env": "dev"}},
+		{Name: "e", Labels: map[string]string{}},
+	}
+
+	expr, err := ParseLabels("env in (prod,staging),tier!=frontend,!canary")
+	require.NoError(t, err)
+
+	filter, err := bexpr.CreateFilterFromAST(expr)
+	require.NoError(t, err)
+	result, err := filter.Execute(pods)
+	require.NoError(t, err)
+	require.Equal(t, []pod{pods[0]}, result)
+
+	expr, err = ParseLabels("env notin (prod),tier")
+	require.NoError(t, err)
+	eval, err := bexpr.CreateEvaluatorFromAST(expr)
+	require.NoError(t, err)
+
+	var names []string
+	for _, p := range pods {
+		match, err := eval.Evaluate(p)
+		require.NoError(t, err)
+		if match {
+			names = append(names, p.Name)
+		}
+	}
+	require.Equal(t, []string{"b"}, names)
+}
@@ -20,6 +20,9 @@ type localVariable struct {
 	name  string
 	path  []string
 	value any
+	// key is set by walkSelectors for the variables standing for the keys
+	// or indexes of collection elements
+	key bool
 }
 
 // Option - how Options are passed as arguments
@@ -32,6 +35,21 @@ type options struct {
 	withHookFn         ValueTransformationHookFn
 	withUnknown        *interface{}
 	withLocalVariables []localVariable
+	withAllowed        []string
+	withDenied         []string
+	withSelectorPolicy *selectorPolicy
+	withInterrupt      *interrupt
+	withRoots          []rootConfig
+	withRootValues     map[string]interface{}
+	withKeyVariable    string
+	// withMissingTopKeys makes the keys missing from a map datum not present
+	// rather than errors, see EvaluateThreeValued
+	withMissingTopKeys bool
+
+	withMaxNodeVisits     uint64
+	withMaxIterations     uint64
+	withMaxRegexInputSize uint64
+	withBudget            *budget
 }
 
 func WithMaxExpressions(maxExprCnt uint64) Option {
@@ -80,6 +98,101 @@ func WithLocalVariable(name string, path []string, value any) Option {
 	}
 }
 
+// WithKeyVariable sets the name of a local variable holding the key of the
+// map entry, or the index of the slice or array element, evaluated by a
+// Filter. For example with WithKeyVariable("Key") a filter can select the
+// entries of a map with `Key matches "^web-"`. The variable shadows the field
+// of the same name of the elements.
+func WithKeyVariable(name string) Option {
+	return func(o *options) {
+		o.withKeyVariable = name
+	}
+}
+
+// rootConfig holds the settings of a root of EvaluateRoots, empty ones
+// defaulting to the settings of the evaluator
+type rootConfig struct {
+	name    string
+	tagName string
+	hookFn  ValueTransformationHookFn
+}
+
+// WithRootOptions sets the options used to look values up in the given root
+// of EvaluateRoots. Only WithTagName and WithHookFn are supported, the roots
+// they are not given for using the ones of the evaluator.
+func WithRootOptions(root string, opts ...Option) Option {
+	var rootOpts options
+	for _, o := range opts {
+		if o != nil {
+			o(&rootOpts)
+		}
+	}
+	return func(o *options) {
+		o.withRoots = append(o.withRoots, rootConfig{
+			name:    root,
+			tagName: rootOpts.withTagName,
+			hookFn:  rootOpts.withHookFn,
+		})
+	}
+}
+
+// WithAllowedSelectors restricts the selectors an expression can reference to
+// the given JSON Pointer patterns and their children. A "*" segment matches
+// any single segment, so "/Labels/*" allows any key of the Labels map. Paths
+// reached through the local variables of "any" and "all" expressions are
+// checked during evaluation, once the keys and indexes are known.
+func WithAllowedSelectors(patterns ...string) Option {
+	return func(o *options) {
+		o.withAllowed = append(o.withAllowed, patterns...)
+	}
+}
+
+// WithDeniedSelectors forbids expressions from referencing the given JSON
+// Pointer patterns and their children. Patterns use the same syntax as
+// WithAllowedSelectors and denied patterns take precedence over allowed ones.
+// Since "in" and "not in" read every element of the collection they are given,
+// they cannot be used on the parents of denied patterns.
+func WithDeniedSelectors(patterns ...string) Option {
+	return func(o *options) {
+		o.withDenied = append(o.withDenied, patterns...)
+	}
+}
+
+// WithMaxNodeVisits limits the number of nodes of the expression each
+// evaluation can visit, counting every visit of the body of the any and all
+// expressions. Evaluations exceeding it fail with a *LimitError.
+func WithMaxNodeVisits(max uint64) Option {
+	return func(o *options) {
+		o.withMaxNodeVisits = max
+	}
+}
+
+// WithMaxIterations limits the total number of iterations each evaluation
+// can do over the elements of collections in any and all expressions, nested
+// ones included. Evaluations exceeding it fail with a *LimitError.
+func WithMaxIterations(max uint64) Option {
+	return func(o *options) {
+		o.withMaxIterations = max
+	}
+}
+
+// WithMaxRegexInputSize limits the size in bytes of the values matched
+// against the regular expressions of matches operators. Evaluations meeting a
+// larger value fail with a *LimitError.
+func WithMaxRegexInputSize(max uint64) Option {
+	return func(o *options) {
+		o.withMaxRegexInputSize = max
+	}
+}
+
+// withSelectorPolicy passes the policy built by CreateEvaluator down to the
+// evaluation
+func withSelectorPolicy(p *selectorPolicy) Option {
+	return func(o *options) {
+		o.withSelectorPolicy = p
+	}
+}
+
 func getDefaultOptions() options {
 	return options{
 		withMaxExpressions: 0,
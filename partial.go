@@ -0,0 +1,151 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// PartialEvaluate evaluates the parts of the expression whose selectors are
+// all known in datum and simplifies the expression around their results. The
+// known selectors are given as JSON Pointer patterns with the same syntax as
+// WithAllowedSelectors, so "/Labels" makes every key of Labels known and
+// "/Items/*/Name" the name of every element of Items.
+//
+// When the result of the expression only depends on known selectors it is
+// returned with a nil residual. Otherwise the residual evaluator implements
+// what remains of the expression, with the options of eval, and is meant to be
+// evaluated once the other selectors are known. Like the evaluators created
+// with CreateEvaluatorFromAST, its Expression is empty.
+//
+// The match and collection expressions are evaluated as a whole, a
+// collection expression being known only when every selector it references
+// is. The known clauses share the budget of a single evaluation. Simplifying
+// assumes the residual evaluates without error, for example
+// `Unknown == 1 and Known == 2` is simplified to false when Known is 3 even
+// though evaluating it could have failed on Unknown. The errors met while
+// evaluating known selectors are returned.
+func (eval *Evaluator) PartialEvaluate(datum interface{}, knownPaths []string) (*Evaluator, bool, error) {
+	p := partial{
+		compiler: compiler{budgeted: eval.budgeted},
+		datum:    datum,
+		opts:     eval.runOptions(nil),
+	}
+	if len(knownPaths) > 0 {
+		policy, err := newSelectorPolicy(knownPaths, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		p.known = policy.allowed
+	}
+
+	residual, err := p.reduce(eval.root)
+	if err != nil {
+		return nil, false, err
+	}
+	if constant, ok := residual.expression.(*grammar.ConstantExpression); ok {
+		return nil, constant.Value, nil
+	}
+
+	// The residual reuses the compiled nodes of eval that were not reduced
+	residualEval, err := configureEvaluator(residual.expression, "", eval.createOpts)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create residual evaluator: %w", err)
+	}
+	residualEval.root = residual
+	return residualEval, false, nil
+}
+
+type partial struct {
+	// compiler compiles the nodes built for the residual
+	compiler compiler
+	datum    interface{}
+	// opts are shared by the known clauses so they consume the same budget
+	opts  *options
+	known [][]string
+}
+
+// isKnown reports whether every selector referenced by ast is known
+func (p *partial) isKnown(ast grammar.Expression) bool {
+	known := true
+	walkSelectors(ast, nil, func(u SelectorUsage) {
+		if !known {
+			return
+		}
+		for _, pattern := range p.known {
+			if matchPattern(pattern, u.Path, true) == patternMatches {
+				return
+			}
+		}
+		known = false
+	})
+	return known
+}
+
+// reduce returns n with the known parts evaluated, a constant node being
+// returned when its result is known
+func (p *partial) reduce(n *compiledNode) (*compiledNode, error) {
+	switch node := n.expression.(type) {
+	case *grammar.UnaryExpression:
+		operand, err := p.reduce(n.children[0])
+		if err != nil {
+			return nil, err
+		}
+		if constant, ok := operand.expression.(*grammar.ConstantExpression); ok {
+			return p.constant(!constant.Value), nil
+		}
+		if operand == n.children[0] {
+			return n, nil
+		}
+		expression := &grammar.UnaryExpression{Operator: node.Operator, Operand: operand.expression}
+		return p.compiler.budget(notNode(expression, operand)), nil
+	case *grammar.BinaryExpression:
+		// The value deciding the result of the expression on its own
+		absorbing := node.Operator == grammar.BinaryOpOr
+
+		left, err := p.reduce(n.children[0])
+		if err != nil {
+			return nil, err
+		}
+		if constant, ok := left.expression.(*grammar.ConstantExpression); ok {
+			if constant.Value == absorbing {
+				return left, nil
+			}
+			return p.reduce(n.children[1])
+		}
+
+		right, err := p.reduce(n.children[1])
+		if err != nil {
+			return nil, err
+		}
+		if constant, ok := right.expression.(*grammar.ConstantExpression); ok {
+			if constant.Value == absorbing {
+				return right, nil
+			}
+			return left, nil
+		}
+		if left == n.children[0] && right == n.children[1] {
+			return n, nil
+		}
+		expression := &grammar.BinaryExpression{Left: left.expression, Operator: node.Operator, Right: right.expression}
+		return p.compiler.budget(binaryNode(expression, left, right)), nil
+	case *grammar.MatchExpression, *grammar.CollectionExpression:
+		if !p.isKnown(node) {
+			return n, nil
+		}
+		result, err := n.program(p.datum, p.opts)
+		if err != nil {
+			return nil, err
+		}
+		return p.constant(result), nil
+	default:
+		return n, nil
+	}
+}
+
+func (p *partial) constant(value bool) *compiledNode {
+	return p.compiler.budget(constantNode(&grammar.ConstantExpression{Value: value}))
+}
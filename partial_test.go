@@ -0,0 +1,160 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartialEvaluate(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		expression string
+		known      []string
+		// residual is the expected residual expression, empty when the
+		// result is definite
+		residual string
+		result   bool
+		err      string
+	}
+
+	datum := map[string]interface{}{
+		"Name":   "web",
+		"Port":   8080,
+		"Labels": map[string]string{"env": "prod"},
+		"Tags":   []string{"a", "b"},
+	}
+
+	tests := map[string]testCase{
+		"all known": {
+			expression: `Name == "web" and Port == 8080`,
+			known:      []string{"/Name", "/Port"},
+			result:     true,
+		},
+		"nothing known": {
+			expression: `Name == "web" and Port == 8080`,
+			residual:   `Name == "web" and Port == 8080`,
+		},
+		"and decided": {
+			expression: `Region == "eu" and Name == "db"`,
+			known:      []string{"/Name"},
+			result:     false,
+		},
+		"and reduced": {
+			expression: `Name == "web" and Region == "eu"`,
+			known:      []string{"/Name"},
+			residual:   `Region == "eu"`,
+		},
+		"or decided": {
+			expression: `Region == "eu" or Name == "web"`,
+			known:      []string{"/Name"},
+			result:     true,
+		},
+		"or reduced": {
+			expression: `Region == "eu" or Name == "db"`,
+			known:      []string{"/Name"},
+			residual:   `Region == "eu"`,
+		},
+		"not": {
+			expression: `not (Name == "web" or Region == "eu") or Zone == "a"`,
+			known:      []string{"/Name"},
+			residual:   `Zone == "a"`,
+		},
+		"not kept": {
+			expression: `not (Name == "db" or Region == "eu")`,
+			known:      []string{"/Name"},
+			residual:   `not Region == "eu"`,
+		},
+		"parent known": {
+			expression: `Labels.env == "prod" and "team" not in Labels and Region == "eu"`,
+			known:      []string{"/Labels"},
+			residual:   `Region == "eu"`,
+		},
+		"wildcard": {
+			expression: `Labels.env == "dev" or Region == "eu"`,
+			known:      []string{"/*/env"},
+			residual:   `Region == "eu"`,
+		},
+		"collection known": {
+			expression: `(any Tags as tag { tag == "b" }) and Region == "eu"`,
+			known:      []string{"/Tags"},
+			residual:   `Region == "eu"`,
+		},
+		"collection partially known": {
+			expression: `(any Tags as tag { tag == "b" and Region == "eu" }) and Name == "web"`,
+			known:      []string{"/Tags", "/Name"},
+			residual:   `any Tags as tag { tag == "b" and Region == "eu" }`,
+		},
+		"error": {
+			expression: `Region == "eu" and Port == "http"`,
+			known:      []string{"/Port"},
+			err:        `error getting match value in expression: strconv.ParseInt: parsing "http": invalid syntax`,
+		},
+		"invalid pattern": {
+			expression: `Name == "web"`,
+			known:      []string{"Name"},
+			err:        `invalid selector pattern "Name": parse Go pointer "Name": first char must be '/'`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			eval, err := CreateEvaluator(tcase.expression)
+			require.NoError(t, err)
+
+			residual, result, err := eval.PartialEvaluate(datum, tcase.known)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tcase.result, result)
+			if tcase.residual == "" {
+				require.Nil(t, residual)
+				return
+			}
+
+			expected, err := grammar.Parse("", []byte(tcase.residual))
+			require.NoError(t, err)
+			require.NotNil(t, residual)
+			require.Equal(t, expected, residual.ast)
+		})
+	}
+}
+
+func TestPartialEvaluate_Residual(t *testing.T) {
+	t.Parallel()
+
+	// The residual keeps the options of the evaluator
+	eval, err := CreateEvaluator(`name == "web" and "prod" in labels`,
+		WithTagName("json"), WithMaxNodeVisits(1))
+	require.NoError(t, err)
+
+	type record struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	}
+	residual, _, err := eval.PartialEvaluate(record{Name: "web"}, []string{"/name"})
+	require.NoError(t, err)
+	require.NotNil(t, residual)
+
+	match, err := residual.Evaluate(record{Labels: map[string]string{"prod": ""}})
+	require.NoError(t, err)
+	require.True(t, match)
+
+	match, err = residual.Evaluate(record{Labels: map[string]string{"dev": ""}})
+	require.NoError(t, err)
+	require.False(t, match)
+
+	// The known clauses share a single budget
+	_, _, err = eval.PartialEvaluate(record{Name: "web"}, []string{"/name", "/labels"})
+	require.EqualError(t, err, "evaluation exceeded the node visits limit of 1 (2 node visits, 0 iterations)")
+}
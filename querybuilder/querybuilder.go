@@ -0,0 +1,497 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package querybuilder converts between bexpr expressions and the JSON
+// queries of react-querybuilder style editors: groups with a combinator and
+// a list of rules, each rule having a field, an operator and a value.
+//
+// Fields are dotted paths, like bexpr selectors, or JSON Pointers when they
+// start with a slash. The following operators are supported:
+//
+//	=, !=                    == and !=
+//	contains, doesNotContain "in" and "not in", with the value first
+//	in, notIn                equality with any of the values, given as an
+//	                         array or a comma separated string
+//	null, notNull            is nil and is not nil
+//	beginsWith, endsWith     imported as regular expression matches, as well
+//	                         as their doesNotBeginWith and doesNotEndWith
+//	                         negations
+//	matches, doesNotMatch    regular expression matches, not part of the
+//	                         default react-querybuilder operators
+//	empty, notEmpty          is empty and is not empty, not part of the
+//	                         default react-querybuilder operators either
+//
+// Rules whose match mode is "some", "all" or "none" are subqueries: their
+// value is a group whose fields are relative to the elements of the field,
+// the empty field being the element itself. They map to "any" and "all"
+// expressions, "none" being a negated "any".
+//
+// Ordering operators, field to field comparisons and the other match modes
+// have no bexpr equivalent and are rejected with an error wrapping
+// ErrUnsupported.
+package querybuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/mitchellh/pointerstructure"
+)
+
+// ErrUnsupported is returned when a query or an expression has no equivalent
+// in the other format.
+var ErrUnsupported = errors.New("unsupported by query builder conversion")
+
+// Rule is either a group of rules, when Combinator is set, or a single rule.
+type Rule struct {
+	ID string `json:"id,omitempty"`
+
+	// Combinator is "and" or "or" for groups
+	Combinator string `json:"combinator,omitempty"`
+	Not        bool   `json:"not,omitempty"`
+	Rules      []Rule `json:"rules,omitempty"`
+
+	Field    string `json:"field,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	// Value is a string, number or boolean, a list of them for the "in" and
+	// "notIn" operators, or the group of a subquery.
+	Value       interface{} `json:"value,omitempty"`
+	ValueSource string      `json:"valueSource,omitempty"`
+	Match       *Match      `json:"match,omitempty"`
+}
+
+// Match is the match mode of a subquery.
+type Match struct {
+	Mode      string `json:"mode"`
+	Threshold int    `json:"threshold,omitempty"`
+}
+
+// IsGroup reports whether r is a group of rules.
+func (r Rule) IsGroup() bool {
+	return r.Combinator != ""
+}
+
+// MarshalJSON always includes the rules of groups, even when empty, as
+// editors expect them.
+func (r Rule) MarshalJSON() ([]byte, error) {
+	type rule Rule
+	if !r.IsGroup() {
+		return json.Marshal(rule(r))
+	}
+	group := struct {
+		ID         string `json:"id,omitempty"`
+		Combinator string `json:"combinator"`
+		Not        bool   `json:"not,omitempty"`
+		Rules      []Rule `json:"rules"`
+	}{ID: r.ID, Combinator: r.Combinator, Not: r.Not, Rules: r.Rules}
+	if group.Rules == nil {
+		group.Rules = []Rule{}
+	}
+	return json.Marshal(group)
+}
+
+// Import converts a query into a bexpr expression.
+func Import(query Rule) (grammar.Expression, error) {
+	return importRule(query, nil)
+}
+
+func importRule(r Rule, scopes []string) (grammar.Expression, error) {
+	var expr grammar.Expression
+	var err error
+	if r.IsGroup() {
+		expr, err = importGroup(r, scopes)
+	} else {
+		expr, err = importMatch(r, scopes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.Not {
+		return &grammar.UnaryExpression{Operator: grammar.UnaryOpNot, Operand: expr}, nil
+	}
+	return expr, nil
+}
+
+func importGroup(r Rule, scopes []string) (grammar.Expression, error) {
+	var op grammar.BinaryOperator
+	switch strings.ToLower(r.Combinator) {
+	case "and":
+		op = grammar.BinaryOpAnd
+	case "or":
+		op = grammar.BinaryOpOr
+	default:
+		return nil, fmt.Errorf("%w: combinator %q", ErrUnsupported, r.Combinator)
+	}
+	if len(r.Rules) == 0 {
+		// An empty group matches everything, like an empty "and"
+		return &grammar.ConstantExpression{Value: op == grammar.BinaryOpAnd}, nil
+	}
+
+	operands := make([]grammar.Expression, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		expr, err := importRule(rule, scopes)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, expr)
+	}
+	// Chain the operands to the right as the parser does
+	result := operands[len(operands)-1]
+	for i := len(operands) - 2; i >= 0; i-- {
+		result = &grammar.BinaryExpression{Left: operands[i], Operator: op, Right: result}
+	}
+	return result, nil
+}
+
+func importSelector(field string, scopes []string) (grammar.Selector, error) {
+	sel := grammar.Selector{Type: grammar.SelectorTypeBexpr}
+	var path []string
+	switch {
+	case strings.HasPrefix(field, "/"):
+		ptr, err := pointerstructure.Parse(field)
+		if err != nil {
+			return grammar.Selector{}, fmt.Errorf("invalid field %q: %w", field, err)
+		}
+		sel.Type = grammar.SelectorTypeJsonPointer
+		path = ptr.Parts
+	case field != "":
+		path = strings.Split(field, ".")
+	}
+
+	if len(scopes) > 0 {
+		path = append([]string{scopes[len(scopes)-1]}, path...)
+	}
+	if len(path) == 0 {
+		return grammar.Selector{}, errors.New("missing field")
+	}
+	sel.Path = path
+	return sel, nil
+}
+
+func importValue(value interface{}) (*grammar.MatchValue, error) {
+	switch v := value.(type) {
+	case string:
+		return &grammar.MatchValue{Raw: v}, nil
+	case float64:
+		return &grammar.MatchValue{Raw: strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case json.Number:
+		return &grammar.MatchValue{Raw: v.String()}, nil
+	case int:
+		return &grammar.MatchValue{Raw: strconv.Itoa(v)}, nil
+	case bool:
+		return &grammar.MatchValue{Raw: strconv.FormatBool(v)}, nil
+	default:
+		return nil, fmt.Errorf("%w: value of type %T", ErrUnsupported, value)
+	}
+}
+
+func importValues(value interface{}) ([]*grammar.MatchValue, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, s := range v {
+			items = append(items, s)
+		}
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			items = append(items, strings.TrimSpace(s))
+		}
+	default:
+		return nil, fmt.Errorf("%w: list of type %T", ErrUnsupported, value)
+	}
+
+	values := make([]*grammar.MatchValue, 0, len(items))
+	for _, item := range items {
+		v, err := importValue(item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func importMatch(r Rule, scopes []string) (grammar.Expression, error) {
+	if r.ValueSource != "" && r.ValueSource != "value" {
+		return nil, fmt.Errorf("%w: value source %q", ErrUnsupported, r.ValueSource)
+	}
+	sel, err := importSelector(r.Field, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if r.Match != nil {
+		return importSubquery(r, sel, scopes)
+	}
+
+	match := &grammar.MatchExpression{Selector: sel}
+	switch r.Operator {
+	case "=", "!=", "contains", "doesNotContain", "matches", "doesNotMatch":
+		match.Value, err = importValue(r.Value)
+		if err != nil {
+			return nil, err
+		}
+		match.Operator = map[string]grammar.MatchOperator{
+			"=":              grammar.MatchEqual,
+			"!=":             grammar.MatchNotEqual,
+			"contains":       grammar.MatchIn,
+			"doesNotContain": grammar.MatchNotIn,
+			"matches":        grammar.MatchMatches,
+			"doesNotMatch":   grammar.MatchNotMatches,
+		}[r.Operator]
+	case "beginsWith", "doesNotBeginWith", "endsWith", "doesNotEndWith":
+		value, err := importValue(r.Value)
+		if err != nil {
+			return nil, err
+		}
+		pattern := "^" + regexp.QuoteMeta(value.Raw)
+		if strings.HasSuffix(r.Operator, "EndWith") || r.Operator == "endsWith" {
+			pattern = regexp.QuoteMeta(value.Raw) + "$"
+		}
+		match.Value = &grammar.MatchValue{Raw: pattern}
+		match.Operator = grammar.MatchMatches
+		if strings.HasPrefix(r.Operator, "doesNot") {
+			match.Operator = grammar.MatchNotMatches
+		}
+	case "in", "notIn":
+		match.Values, err = importValues(r.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(match.Values) == 0 {
+			return &grammar.ConstantExpression{Value: r.Operator == "notIn"}, nil
+		}
+		match.Operator = grammar.MatchIsOneOf
+		if r.Operator == "notIn" {
+			match.Operator = grammar.MatchIsNotOneOf
+		}
+	case "null":
+		match.Operator = grammar.MatchIsNil
+	case "notNull":
+		match.Operator = grammar.MatchIsNotNil
+	case "empty":
+		match.Operator = grammar.MatchIsEmpty
+	case "notEmpty":
+		match.Operator = grammar.MatchIsNotEmpty
+	default:
+		return nil, fmt.Errorf("%w: operator %q", ErrUnsupported, r.Operator)
+	}
+	return match, nil
+}
+
+func importSubquery(r Rule, sel grammar.Selector, scopes []string) (grammar.Expression, error) {
+	var group Rule
+	switch v := r.Value.(type) {
+	case Rule:
+		group = v
+	case *Rule:
+		group = *v
+	default:
+		// Decoded from JSON into an interface{}
+		data, err := json.Marshal(r.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subquery on %q: %w", r.Field, err)
+		}
+		if err := json.Unmarshal(data, &group); err != nil {
+			return nil, fmt.Errorf("invalid subquery on %q: %w", r.Field, err)
+		}
+	}
+
+	name := fmt.Sprintf("v%d", len(scopes))
+	inner, err := importRule(group, append(scopes, name))
+	if err != nil {
+		return nil, err
+	}
+	expr := &grammar.CollectionExpression{
+		Op:       grammar.CollectionOpAny,
+		Selector: sel,
+		Inner:    inner,
+		NameBinding: grammar.CollectionNameBinding{
+			Mode:    grammar.CollectionBindDefault,
+			Default: name,
+		},
+	}
+
+	switch r.Match.Mode {
+	case "some":
+		return expr, nil
+	case "all":
+		expr.Op = grammar.CollectionOpAll
+		return expr, nil
+	case "none":
+		return &grammar.UnaryExpression{Operator: grammar.UnaryOpNot, Operand: expr}, nil
+	default:
+		return nil, fmt.Errorf("%w: match mode %q", ErrUnsupported, r.Match.Mode)
+	}
+}
+
+// Export converts expr into a query, whose root is always a group.
+func Export(expr grammar.Expression) (Rule, error) {
+	r, err := exportRule(expr, nil)
+	if err != nil {
+		return Rule{}, err
+	}
+	if !r.IsGroup() {
+		r = Rule{Combinator: "and", Rules: []Rule{r}}
+	}
+	return r, nil
+}
+
+func exportRule(expr grammar.Expression, scopes []string) (Rule, error) {
+	switch node := expr.(type) {
+	case *grammar.ConstantExpression:
+		if node.Value {
+			return Rule{Combinator: "and"}, nil
+		}
+		return Rule{Combinator: "or"}, nil
+	case *grammar.UnaryExpression:
+		if node.Operator != grammar.UnaryOpNot {
+			return Rule{}, fmt.Errorf("invalid unary operator: %s", node.Operator)
+		}
+		r, err := exportRule(node.Operand, scopes)
+		if err != nil {
+			return Rule{}, err
+		}
+		if !r.IsGroup() || r.Not {
+			r = Rule{Combinator: "and", Rules: []Rule{r}}
+		}
+		r.Not = true
+		return r, nil
+	case *grammar.BinaryExpression:
+		r := Rule{}
+		switch node.Operator {
+		case grammar.BinaryOpAnd:
+			r.Combinator = "and"
+		case grammar.BinaryOpOr:
+			r.Combinator = "or"
+		default:
+			return Rule{}, fmt.Errorf("invalid binary operator: %s", node.Operator)
+		}
+		for _, operand := range grammar.Operands(node, node.Operator) {
+			rule, err := exportRule(operand, scopes)
+			if err != nil {
+				return Rule{}, err
+			}
+			r.Rules = append(r.Rules, rule)
+		}
+		return r, nil
+	case *grammar.MatchExpression:
+		return exportMatch(node, scopes)
+	case *grammar.CollectionExpression:
+		return exportCollection(node, scopes)
+	default:
+		return Rule{}, fmt.Errorf("invalid AST node: %T", expr)
+	}
+}
+
+// exportField converts sel into a field, relative to the innermost
+// collection if any.
+func exportField(sel grammar.Selector, scopes []string) (string, error) {
+	path := sel.Path
+	if len(scopes) > 0 {
+		inner := scopes[len(scopes)-1]
+		if len(path) == 0 || path[0] != inner {
+			return "", fmt.Errorf("%w: %q must reference the elements of the enclosing collection %q", ErrUnsupported, sel.String(), inner)
+		}
+		path = path[1:]
+	}
+	if len(path) == 0 {
+		return "", nil
+	}
+
+	pointer := sel.Type == grammar.SelectorTypeJsonPointer
+	for _, part := range path {
+		if part == "" || strings.Contains(part, ".") {
+			pointer = true
+		}
+	}
+	if pointer {
+		ptr := pointerstructure.Pointer{Parts: path}
+		return ptr.String(), nil
+	}
+	return strings.Join(path, "."), nil
+}
+
+func exportMatch(expr *grammar.MatchExpression, scopes []string) (Rule, error) {
+	field, err := exportField(expr.Selector, scopes)
+	if err != nil {
+		return Rule{}, err
+	}
+	r := Rule{Field: field}
+
+	switch expr.Operator {
+	case grammar.MatchEqual:
+		r.Operator = "="
+	case grammar.MatchNotEqual:
+		r.Operator = "!="
+	case grammar.MatchIn:
+		r.Operator = "contains"
+	case grammar.MatchNotIn:
+		r.Operator = "doesNotContain"
+	case grammar.MatchMatches:
+		r.Operator = "matches"
+	case grammar.MatchNotMatches:
+		r.Operator = "doesNotMatch"
+	case grammar.MatchIsNil:
+		r.Operator = "null"
+	case grammar.MatchIsNotNil:
+		r.Operator = "notNull"
+	case grammar.MatchIsEmpty:
+		r.Operator = "empty"
+	case grammar.MatchIsNotEmpty:
+		r.Operator = "notEmpty"
+	case grammar.MatchIsOneOf, grammar.MatchIsNotOneOf:
+		r.Operator = "in"
+		if expr.Operator == grammar.MatchIsNotOneOf {
+			r.Operator = "notIn"
+		}
+		values := make([]interface{}, 0, len(expr.Values))
+		for _, v := range expr.Values {
+			values = append(values, v.Raw)
+		}
+		r.Value = values
+		return r, nil
+	default:
+		return Rule{}, fmt.Errorf("invalid match operation: %d", expr.Operator)
+	}
+	if expr.Value != nil {
+		r.Value = expr.Value.Raw
+	}
+	return r, nil
+}
+
+func exportCollection(expr *grammar.CollectionExpression, scopes []string) (Rule, error) {
+	var name string
+	switch expr.NameBinding.Mode {
+	case grammar.CollectionBindDefault:
+		name = expr.NameBinding.Default
+	case grammar.CollectionBindValue:
+		name = expr.NameBinding.Value
+	default:
+		return Rule{}, fmt.Errorf("%w: binding the index of %s", ErrUnsupported, expr.Selector)
+	}
+
+	field, err := exportField(expr.Selector, scopes)
+	if err != nil {
+		return Rule{}, err
+	}
+	inner, err := exportRule(expr.Inner, append(scopes, name))
+	if err != nil {
+		return Rule{}, err
+	}
+	if !inner.IsGroup() {
+		inner = Rule{Combinator: "and", Rules: []Rule{inner}}
+	}
+
+	mode := "some"
+	if expr.Op == grammar.CollectionOpAll {
+		mode = "all"
+	}
+	return Rule{Field: field, Operator: "=", Match: &Match{Mode: mode}, Value: inner}, nil
+}
@@ -0,0 +1,276 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package querybuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, input string) grammar.Expression {
+	t.Helper()
+	expr, err := grammar.Parse("", []byte(input))
+	require.NoError(t, err)
+	return expr.(grammar.Expression)
+}
+
+func dump(expr grammar.Expression) string {
+	var buf bytes.Buffer
+	expr.ExpressionDump(&buf, "   ", 0)
+	return buf.String()
+}
+
+func decode(t *testing.T, query string) Rule {
+	t.Helper()
+	var r Rule
+	require.NoError(t, json.Unmarshal([]byte(query), &r))
+	return r
+}
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		query    string
+		expected string
+		oneOf    bool
+		err      string
+	}
+
+	tests := map[string]testCase{
+		"rules": {
+			query: `{"combinator": "and", "rules": [
+				{"field": "Name", "operator": "=", "value": "web"},
+				{"field": "Port", "operator": "!=", "value": 8080},
+				{"field": "Enabled", "operator": "=", "value": true}
+			]}`,
+			expected: `Name == web and Port != 8080 and Enabled == true`,
+		},
+		"nested groups": {
+			query: `{"combinator": "or", "rules": [
+				{"field": "Tags", "operator": "contains", "value": "prod"},
+				{"combinator": "and", "not": true, "rules": [
+					{"field": "Meta.owner", "operator": "null"},
+					{"field": "Meta.team", "operator": "notEmpty"}
+				]}
+			]}`,
+			expected: `"prod" in Tags or not (Meta.owner is nil and Meta.team is not empty)`,
+		},
+		"single rule group": {
+			query:    `{"combinator": "and", "rules": [{"field": "Name", "operator": "doesNotContain", "value": "test"}]}`,
+			expected: `Name not contains test`,
+		},
+		"json pointer field": {
+			query:    `{"combinator": "and", "rules": [{"field": "/Labels/app.kubernetes.io~1name", "operator": "=", "value": "web"}]}`,
+			expected: `"/Labels/app.kubernetes.io~1name" == web`,
+		},
+		"begins with": {
+			query:    `{"combinator": "and", "rules": [{"field": "Name", "operator": "beginsWith", "value": "web."}, {"field": "Name", "operator": "doesNotEndWith", "value": "-1"}]}`,
+			expected: `Name matches "^web\\." and Name not matches "-1$"`,
+		},
+		"in": {
+			query:    `{"combinator": "and", "rules": [{"field": "Env", "operator": "in", "value": "dev, test"}]}`,
+			expected: `Env == dev or Env == test`,
+			oneOf:    true,
+		},
+		"not in": {
+			query:    `{"combinator": "and", "rules": [{"field": "Env", "operator": "notIn", "value": ["dev", "test"]}]}`,
+			expected: `Env != dev and Env != test`,
+			oneOf:    true,
+		},
+		"subqueries": {
+			query: `{"combinator": "and", "rules": [
+				{"field": "Checks", "operator": "=", "match": {"mode": "some"}, "value": {"combinator": "and", "rules": [
+					{"field": "Status", "operator": "=", "value": "passing"}
+				]}},
+				{"field": "Tags", "operator": "=", "match": {"mode": "none"}, "value": {"combinator": "and", "rules": [
+					{"field": "", "operator": "=", "value": "deprecated"}
+				]}},
+				{"field": "Ports", "operator": "=", "match": {"mode": "all"}, "value": {"combinator": "and", "rules": [
+					{"field": "", "operator": "!=", "value": "22"}
+				]}}
+			]}`,
+			expected: `(any Checks as v0 { v0.Status == passing }) and (not (any Tags as v0 { v0 == deprecated })) and (all Ports as v0 { v0 != "22" })`,
+		},
+		"ordering operator": {
+			query: `{"combinator": "and", "rules": [{"field": "Port", "operator": ">", "value": 1024}]}`,
+			err:   `unsupported by query builder conversion: operator ">"`,
+		},
+		"field source": {
+			query: `{"combinator": "and", "rules": [{"field": "A", "operator": "=", "value": "B", "valueSource": "field"}]}`,
+			err:   `unsupported by query builder conversion: value source "field"`,
+		},
+		"match mode": {
+			query: `{"combinator": "and", "rules": [{"field": "A", "operator": "=", "match": {"mode": "atLeast", "threshold": 2}, "value": {"combinator": "and", "rules": []}}]}`,
+			err:   `unsupported by query builder conversion: match mode "atLeast"`,
+		},
+		"combinator": {
+			query: `{"combinator": "xor", "rules": []}`,
+			err:   `unsupported by query builder conversion: combinator "xor"`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := Import(decode(t, tcase.query))
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				require.True(t, errors.Is(err, ErrUnsupported))
+				return
+			}
+			require.NoError(t, err)
+
+			expected := parse(t, tcase.expected)
+			if tcase.oneOf {
+				expected = grammar.Simplify(expected)
+			}
+			require.Equal(t, dump(expected), dump(expr))
+		})
+	}
+}
+
+func TestImport_Empty(t *testing.T) {
+	t.Parallel()
+
+	expr, err := Import(Rule{Combinator: "and"})
+	require.NoError(t, err)
+	require.Equal(t, &grammar.ConstantExpression{Value: true}, expr)
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		input    string
+		expected string
+		simplify bool
+		err      string
+	}
+
+	tests := map[string]testCase{
+		"single rule": {
+			input:    `Name == web`,
+			expected: `{"combinator":"and","rules":[{"field":"Name","operator":"=","value":"web"}]}`,
+		},
+		"groups": {
+			input:    `Port != 8080 or not ("x" in Tags and Meta.team is empty)`,
+			expected: `{"combinator":"or","rules":[{"field":"Port","operator":"!=","value":"8080"},{"combinator":"and","not":true,"rules":[{"field":"Tags","operator":"contains","value":"x"},{"field":"Meta.team","operator":"empty"}]}]}`,
+		},
+		"negated rule": {
+			input:    `not Name matches "^web"`,
+			expected: `{"combinator":"and","not":true,"rules":[{"field":"Name","operator":"matches","value":"^web"}]}`,
+		},
+		"one of": {
+			input:    `Env == dev or Env == test`,
+			expected: `{"combinator":"and","rules":[{"field":"Env","operator":"in","value":["dev","test"]}]}`,
+			simplify: true,
+		},
+		"constant": {
+			input:    `Env == dev and Env == test`,
+			expected: `{"combinator":"or","rules":[]}`,
+			simplify: true,
+		},
+		"dotted key": {
+			input:    `Labels["app.kubernetes.io/name"] == web`,
+			expected: `{"combinator":"and","rules":[{"field":"/Labels/app.kubernetes.io~1name","operator":"=","value":"web"}]}`,
+		},
+		"subquery": {
+			input:    `all Checks as _, c { c.Status == passing }`,
+			expected: `{"combinator":"and","rules":[{"field":"Checks","operator":"=","match":{"mode":"all"},"value":{"combinator":"and","rules":[{"field":"Status","operator":"=","value":"passing"}]}}]}`,
+		},
+		"outer reference": {
+			input: `any Checks as c { Name == web }`,
+			err:   `unsupported by query builder conversion: "Name" must reference the elements of the enclosing collection "c"`,
+		},
+		"index binding": {
+			input: `any Checks as i, c { i == 0 }`,
+			err:   `unsupported by query builder conversion: binding the index of Checks`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr := parse(t, tcase.input)
+			if tcase.simplify {
+				expr = grammar.Simplify(expr)
+			}
+			query, err := Export(expr)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := json.Marshal(query)
+			require.NoError(t, err)
+			require.JSONEq(t, tcase.expected, string(data))
+		})
+	}
+}
+
+func TestRoundTrip_Expression(t *testing.T) {
+	t.Parallel()
+
+	inputs := map[string]string{
+		"matches":     `Name == web and (Port != 8080 or "x" in Tags) and Name not matches "^test"`,
+		"negations":   `not (A is empty or B is not nil) and not C == 1 and not not D == 2`,
+		"pointer":     `"/Labels/app.kubernetes.io~1name" == web`,
+		"collections": `(any Checks as v0 { v0.Status == passing and (all v0.Notes as v1 { v1 != "" }) }) or (not (any Tags as v0 { v0 == old }))`,
+	}
+
+	for name, input := range inputs {
+		input := input
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr := parse(t, input)
+			query, err := Export(expr)
+			require.NoError(t, err)
+
+			// Go through JSON as the editor would
+			data, err := json.Marshal(query)
+			require.NoError(t, err)
+			imported, err := Import(decode(t, string(data)))
+			require.NoError(t, err)
+			require.Equal(t, dump(expr), dump(imported))
+		})
+	}
+}
+
+func TestRoundTrip_Query(t *testing.T) {
+	t.Parallel()
+
+	queries := map[string]string{
+		"flat":   `{"combinator":"and","rules":[{"field":"Name","operator":"=","value":"web"},{"field":"Env","operator":"in","value":["dev","test"]}]}`,
+		"nested": `{"combinator":"or","rules":[{"field":"A","operator":"null"},{"combinator":"and","not":true,"rules":[{"field":"B","operator":"notEmpty"},{"field":"C","operator":"doesNotMatch","value":"x+"}]}]}`,
+		"empty":  `{"combinator":"and","rules":[]}`,
+	}
+
+	for name, query := range queries {
+		query := query
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := Import(decode(t, query))
+			require.NoError(t, err)
+			exported, err := Export(expr)
+			require.NoError(t, err)
+
+			data, err := json.Marshal(exported)
+			require.NoError(t, err)
+			require.JSONEq(t, query, string(data))
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package querydsl translates bexpr expressions into Elasticsearch and
+// OpenSearch query DSL.
+//
+// The translation follows the bexpr semantics as closely as the query DSL
+// allows, with the following approximations:
+//
+//   - Missing fields behave like missing map keys: ==, "in", "is not empty"
+//     and "matches" do not match them while their negations do, as the
+//     NotPresentDisposition of the operators specifies. The query DSL does not
+//     distinguish a missing field from a null one or an empty array though, so
+//     "is empty" and "is nil" both become a negated "exists" query, which also
+//     means an empty string is not considered empty.
+//   - "in" and "contains" become a "term" query: they check that an array
+//     field contains the value but, unlike bexpr, do not look for substrings
+//     in text fields.
+//   - "matches" becomes a "regexp" query, which uses the Lucene regular
+//     expression syntax and is anchored to the whole value while Go regular
+//     expressions are not.
+//   - "any" and "all" become "nested" queries and can only be used on fields
+//     mapped with the nested type, on their values and without referencing
+//     fields outside of the nested documents.
+package querydsl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// ErrUnsupported is returned when an expression cannot be expressed in query
+// DSL.
+var ErrUnsupported = errors.New("unsupported by query DSL")
+
+// FieldMapper returns the name of the document field at path.
+type FieldMapper func(path []string) (string, error)
+
+// Config configures Translate.
+type Config struct {
+	// Fields maps the paths of selectors to field names. When nil the
+	// segments of the paths are joined with dots.
+	Fields FieldMapper
+}
+
+// Query is a query DSL object, ready to be encoded to JSON.
+type Query = map[string]interface{}
+
+// Translate converts expr into a query DSL query.
+func Translate(expr grammar.Expression, config Config) (Query, error) {
+	if config.Fields == nil {
+		config.Fields = func(path []string) (string, error) {
+			return strings.Join(path, "."), nil
+		}
+	}
+	t := translator{config: config}
+	return t.translate(expr, nil)
+}
+
+// scope is a local variable introduced by a collection expression, it stands
+// for the nested documents at path.
+type scope struct {
+	name string
+	path []string
+}
+
+type translator struct {
+	config Config
+}
+
+func boolQuery(clause string, queries ...Query) Query {
+	return Query{"bool": Query{clause: queries}}
+}
+
+func (t *translator) translate(expr grammar.Expression, scopes []scope) (Query, error) {
+	switch node := expr.(type) {
+	case *grammar.UnaryExpression:
+		if node.Operator != grammar.UnaryOpNot {
+			return nil, fmt.Errorf("invalid unary operator: %s", node.Operator)
+		}
+		operand, err := t.translate(node.Operand, scopes)
+		if err != nil {
+			return nil, err
+		}
+		return boolQuery("must_not", operand), nil
+	case *grammar.BinaryExpression:
+		var queries []Query
+		for _, operand := range grammar.Operands(node, node.Operator) {
+			q, err := t.translate(operand, scopes)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, q)
+		}
+		switch node.Operator {
+		case grammar.BinaryOpAnd:
+			return boolQuery("filter", queries...), nil
+		case grammar.BinaryOpOr:
+			q := boolQuery("should", queries...)
+			q["bool"].(Query)["minimum_should_match"] = 1
+			return q, nil
+		default:
+			return nil, fmt.Errorf("invalid binary operator: %s", node.Operator)
+		}
+	case *grammar.MatchExpression:
+		return t.translateMatch(node, scopes)
+	case *grammar.CollectionExpression:
+		return t.translateCollection(node, scopes)
+	case *grammar.ConstantExpression:
+		if node.Value {
+			return Query{"match_all": Query{}}, nil
+		}
+		return Query{"match_none": Query{}}, nil
+	default:
+		return nil, fmt.Errorf("invalid AST node: %T", expr)
+	}
+}
+
+// resolve returns the path of the document field referenced by sel, the
+// innermost scope being the only one accessible from a nested query.
+func resolve(sel grammar.Selector, scopes []scope) ([]string, error) {
+	if len(scopes) == 0 {
+		return sel.Path, nil
+	}
+	inner := scopes[len(scopes)-1]
+	if len(sel.Path) == 0 || sel.Path[0] != inner.name {
+		return nil, fmt.Errorf("%w: %q must reference the elements of the enclosing collection %q", ErrUnsupported, sel.String(), inner.name)
+	}
+	return append(append([]string(nil), inner.path...), sel.Path[1:]...), nil
+}
+
+func (t *translator) translateMatch(expr *grammar.MatchExpression, scopes []scope) (Query, error) {
+	path, err := resolve(expr.Selector, scopes)
+	if err != nil {
+		return nil, err
+	}
+	field, err := t.config.Fields(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var q Query
+	negate := false
+	switch expr.Operator {
+	case grammar.MatchEqual, grammar.MatchIn:
+		q = Query{"term": Query{field: expr.Value.Raw}}
+	case grammar.MatchNotEqual, grammar.MatchNotIn:
+		q, negate = Query{"term": Query{field: expr.Value.Raw}}, true
+	case grammar.MatchIsOneOf, grammar.MatchIsNotOneOf:
+		values := make([]interface{}, 0, len(expr.Values))
+		for _, v := range expr.Values {
+			values = append(values, v.Raw)
+		}
+		q, negate = Query{"terms": Query{field: values}}, expr.Operator == grammar.MatchIsNotOneOf
+	case grammar.MatchMatches:
+		q = Query{"regexp": Query{field: Query{"value": expr.Value.Raw}}}
+	case grammar.MatchNotMatches:
+		q, negate = Query{"regexp": Query{field: Query{"value": expr.Value.Raw}}}, true
+	case grammar.MatchIsNotEmpty, grammar.MatchIsNotNil:
+		q = Query{"exists": Query{"field": field}}
+	case grammar.MatchIsEmpty, grammar.MatchIsNil:
+		q, negate = Query{"exists": Query{"field": field}}, true
+	default:
+		return nil, fmt.Errorf("invalid match operation: %d", expr.Operator)
+	}
+
+	if negate {
+		return boolQuery("must_not", q), nil
+	}
+	return q, nil
+}
+
+func (t *translator) translateCollection(expr *grammar.CollectionExpression, scopes []scope) (Query, error) {
+	var name string
+	switch expr.NameBinding.Mode {
+	case grammar.CollectionBindDefault:
+		name = expr.NameBinding.Default
+	case grammar.CollectionBindValue:
+		name = expr.NameBinding.Value
+	default:
+		return nil, fmt.Errorf("%w: binding the index of %s", ErrUnsupported, expr.Selector)
+	}
+
+	path, err := resolve(expr.Selector, scopes)
+	if err != nil {
+		return nil, err
+	}
+	field, err := t.config.Fields(path)
+	if err != nil {
+		return nil, err
+	}
+
+	inner, err := t.translate(expr.Inner, append(scopes, scope{name: name, path: path}))
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.Op {
+	case grammar.CollectionOpAny:
+		return Query{"nested": Query{"path": field, "query": inner}}, nil
+	case grammar.CollectionOpAll:
+		// all X { p } is not any X { not p }, which also holds for missing
+		// and empty collections
+		nested := Query{"nested": Query{"path": field, "query": boolQuery("must_not", inner)}}
+		return boolQuery("must_not", nested), nil
+	default:
+		return nil, fmt.Errorf("invalid collection operator: %s", expr.Op)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package querydsl
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, input string) grammar.Expression {
+	t.Helper()
+	expr, err := grammar.Parse("", []byte(input))
+	require.NoError(t, err)
+	return expr.(grammar.Expression)
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		input    string
+		fields   FieldMapper
+		query    string
+		err      string
+		simplify bool
+	}
+
+	tests := map[string]testCase{
+		"equal": {
+			input: `Name == "web"`,
+			query: `{"term":{"Name":"web"}}`,
+		},
+		"not equal": {
+			input: `Name != "web"`,
+			query: `{"bool":{"must_not":[{"term":{"Name":"web"}}]}}`,
+		},
+		"in": {
+			input: `"prod" in Meta.Tags`,
+			query: `{"term":{"Meta.Tags":"prod"}}`,
+		},
+		"not in": {
+			input: `"prod" not in Tags`,
+			query: `{"bool":{"must_not":[{"term":{"Tags":"prod"}}]}}`,
+		},
+		"is empty": {
+			input: `Tags is empty`,
+			query: `{"bool":{"must_not":[{"exists":{"field":"Tags"}}]}}`,
+		},
+		"is not nil": {
+			input: `Tags is not nil`,
+			query: `{"exists":{"field":"Tags"}}`,
+		},
+		"matches": {
+			input: `Name matches "web-.*"`,
+			query: `{"regexp":{"Name":{"value":"web-.*"}}}`,
+		},
+		"not matches": {
+			input: `Name not matches "web-.*"`,
+			query: `{"bool":{"must_not":[{"regexp":{"Name":{"value":"web-.*"}}}]}}`,
+		},
+		"and or not": {
+			input: `Name == "a" and Port == "1" and not (Env == "dev" or Env == "test")`,
+			query: `{"bool":{"filter":[{"term":{"Name":"a"}},{"term":{"Port":"1"}},{"bool":{"must_not":[{"bool":{"minimum_should_match":1,"should":[{"term":{"Env":"dev"}},{"term":{"Env":"test"}}]}}]}}]}}`,
+		},
+		"simplified one of": {
+			input:    `Env == "dev" or Env == "test"`,
+			query:    `{"terms":{"Env":["dev","test"]}}`,
+			simplify: true,
+		},
+		"simplified not one of": {
+			input:    `Env != "dev" and Env != "test"`,
+			query:    `{"bool":{"must_not":[{"terms":{"Env":["dev","test"]}}]}}`,
+			simplify: true,
+		},
+		"simplified constant": {
+			input:    `Env == "dev" and Env == "test"`,
+			query:    `{"match_none":{}}`,
+			simplify: true,
+		},
+		"any": {
+			input: `any Checks as c { c.Status == "passing" and c.Name == "web" }`,
+			query: `{"nested":{"path":"Checks","query":{"bool":{"filter":[{"term":{"Checks.Status":"passing"}},{"term":{"Checks.Name":"web"}}]}}}}`,
+		},
+		"all": {
+			input: `all Checks as _, c { c.Status == "passing" }`,
+			query: `{"bool":{"must_not":[{"nested":{"path":"Checks","query":{"bool":{"must_not":[{"term":{"Checks.Status":"passing"}}]}}}}]}}`,
+		},
+		"nested collections": {
+			input: `any Services as s { any s.Checks as c { c.Status == "passing" } }`,
+			query: `{"nested":{"path":"Services","query":{"nested":{"path":"Services.Checks","query":{"term":{"Services.Checks.Status":"passing"}}}}}}`,
+		},
+		"field mapper": {
+			input: `any Checks as c { c.Status == "passing" }`,
+			fields: func(path []string) (string, error) {
+				return strings.ToLower(strings.Join(path, "_")), nil
+			},
+			query: `{"nested":{"path":"checks","query":{"term":{"checks_status":"passing"}}}}`,
+		},
+		"field mapper error": {
+			input: `Secret == "x"`,
+			fields: func(path []string) (string, error) {
+				return "", errors.New("unknown field")
+			},
+			err: "unknown field",
+		},
+		"outer reference": {
+			input: `any Checks as c { Name == "web" }`,
+			err:   `unsupported by query DSL: "Name" must reference the elements of the enclosing collection "c"`,
+		},
+		"index binding": {
+			input: `any Checks as i, c { c.Status == "passing" }`,
+			err:   "unsupported by query DSL: binding the index of Checks",
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr := parse(t, tcase.input)
+			if tcase.simplify {
+				expr = grammar.Simplify(expr)
+			}
+
+			query, err := Translate(expr, Config{Fields: tcase.fields})
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+
+			encoded, err := json.Marshal(query)
+			require.NoError(t, err)
+			require.JSONEq(t, tcase.query, string(encoded))
+		})
+	}
+}
+
+func TestTranslate_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := Translate(parse(t, `any Checks as i, c { c.Status == "passing" }`), Config{})
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
@@ -0,0 +1,128 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/mitchellh/pointerstructure"
+)
+
+// SelectorWildcard is the path segment used in SelectorUsage.Path in place of
+// the key or index of an element of a collection iterated by an "any" or
+// "all" expression, as it is only known during evaluation.
+const SelectorWildcard = "*"
+
+// SelectorUsage describes a single reference to a selector in an expression
+// and the context it is used in.
+type SelectorUsage struct {
+	// Selector is the selector as it was written in the expression.
+	Selector grammar.Selector
+
+	// MatchOperator is the operator of the match expression using the
+	// selector. It is only meaningful when CollectionOperator is empty.
+	MatchOperator grammar.MatchOperator
+
+	// CollectionOperator is set when the selector is the collection iterated
+	// by an "any" or "all" expression.
+	CollectionOperator grammar.CollectionOperator
+
+	// InCollection is true when the selector is used inside the body of an
+	// "any" or "all" expression.
+	InCollection bool
+
+	// LocalVariable is the name of the local variable, introduced by a
+	// collection name binding, the selector starts with. It is empty when the
+	// selector references the datum directly.
+	LocalVariable string
+
+	// Path is the absolute path referenced by the selector, with local
+	// variables replaced by the path of the collection elements they stand
+	// for. The keys and indexes of those elements are represented by
+	// SelectorWildcard.
+	Path []string
+
+	// Key is true when LocalVariable is the index variable of a name binding,
+	// like k in `any Map as k, v`, which stands for the key or index of the
+	// element at Path rather than for the element itself. The variable of
+	// `any Map as k` stands for the keys of maps but the elements of slices,
+	// which is only known during evaluation, so Key is false for it.
+	Key bool
+}
+
+// Pointer returns the absolute path of the selector as a JSON Pointer.
+func (u SelectorUsage) Pointer() string {
+	ptr := pointerstructure.Pointer{Parts: u.Path}
+	return ptr.String()
+}
+
+// Selectors returns all the selectors referenced by the expression, in the
+// order they appear in it.
+func (eval *Evaluator) Selectors() []SelectorUsage {
+	var usages []SelectorUsage
+	walkSelectors(eval.ast, nil, func(u SelectorUsage) {
+		usages = append(usages, u)
+	})
+	return usages
+}
+
+// walkSelectors calls fn for each selector found in ast. locals holds the
+// local variables in scope, the innermost last.
+func walkSelectors(ast grammar.Expression, locals []localVariable, fn func(SelectorUsage)) {
+	switch node := ast.(type) {
+	case *grammar.UnaryExpression:
+		walkSelectors(node.Operand, locals, fn)
+	case *grammar.BinaryExpression:
+		walkSelectors(node.Left, locals, fn)
+		walkSelectors(node.Right, locals, fn)
+	case *grammar.MatchExpression:
+		usage := resolveSelector(node.Selector, locals)
+		usage.MatchOperator = node.Operator
+		fn(usage)
+	case *grammar.CollectionExpression:
+		usage := resolveSelector(node.Selector, locals)
+		usage.CollectionOperator = node.Op
+		fn(usage)
+
+		// Whether the index or the value is bound, the local variables
+		// always stand for an element of the collection.
+		elem := make([]string, 0, len(usage.Path)+1)
+		elem = append(elem, usage.Path...)
+		elem = append(elem, SelectorWildcard)
+
+		inner := append([]localVariable(nil), locals...)
+		binding := node.NameBinding
+		if binding.Default != "" {
+			inner = append(inner, localVariable{name: binding.Default, path: elem})
+		}
+		if binding.Index != "" {
+			inner = append(inner, localVariable{name: binding.Index, path: elem, key: true})
+		}
+		if binding.Value != "" {
+			inner = append(inner, localVariable{name: binding.Value, path: elem})
+		}
+		walkSelectors(node.Inner, inner, fn)
+	}
+}
+
+// resolveSelector computes the absolute path of sel the same way lookupValue
+// does during evaluation.
+func resolveSelector(sel grammar.Selector, locals []localVariable) SelectorUsage {
+	usage := SelectorUsage{
+		Selector:     sel,
+		InCollection: len(locals) > 0,
+		Path:         append([]string(nil), sel.Path...),
+	}
+	for i := len(locals) - 1; i >= 0; i-- {
+		if len(usage.Path) == 0 || usage.Path[0] != locals[i].name {
+			continue
+		}
+		if usage.LocalVariable == "" {
+			usage.LocalVariable = locals[i].name
+			usage.Key = locals[i].key
+		}
+		prefix := append([]string(nil), locals[i].path...)
+		usage.Path = append(prefix, usage.Path[1:]...)
+	}
+	return usage
+}
@@ -0,0 +1,100 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectors(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		expression string
+		expected   []SelectorUsage
+	}
+
+	bexprSel := func(path ...string) grammar.Selector {
+		return grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: path}
+	}
+
+	tests := map[string]testCase{
+		"match": {
+			expression: "foo.bar == 3",
+			expected: []SelectorUsage{
+				{Selector: bexprSel("foo", "bar"), MatchOperator: grammar.MatchEqual, Path: []string{"foo", "bar"}},
+			},
+		},
+		"binary and unary": {
+			expression: `not (foo is empty or "/bar/baz" matches "x") and 1 in qux`,
+			expected: []SelectorUsage{
+				{Selector: bexprSel("foo"), MatchOperator: grammar.MatchIsEmpty, Path: []string{"foo"}},
+				{Selector: grammar.Selector{Type: grammar.SelectorTypeJsonPointer, Path: []string{"bar", "baz"}}, MatchOperator: grammar.MatchMatches, Path: []string{"bar", "baz"}},
+				{Selector: bexprSel("qux"), MatchOperator: grammar.MatchIn, Path: []string{"qux"}},
+			},
+		},
+		"collection": {
+			expression: `all Slice as item { item.X == 1 and Top != 2 }`,
+			expected: []SelectorUsage{
+				{Selector: bexprSel("Slice"), CollectionOperator: grammar.CollectionOpAll, Path: []string{"Slice"}},
+				{Selector: bexprSel("item", "X"), MatchOperator: grammar.MatchEqual, InCollection: true, LocalVariable: "item", Path: []string{"Slice", "*", "X"}},
+				{Selector: bexprSel("Top"), MatchOperator: grammar.MatchNotEqual, InCollection: true, Path: []string{"Top"}},
+			},
+		},
+		"nested collections": {
+			expression: `any Map as k, v { all v.Items as _, i { i == k } }`,
+			expected: []SelectorUsage{
+				{Selector: bexprSel("Map"), CollectionOperator: grammar.CollectionOpAny, Path: []string{"Map"}},
+				{Selector: bexprSel("v", "Items"), CollectionOperator: grammar.CollectionOpAll, InCollection: true, LocalVariable: "v", Path: []string{"Map", "*", "Items"}},
+				{Selector: bexprSel("i"), MatchOperator: grammar.MatchEqual, InCollection: true, LocalVariable: "i", Path: []string{"Map", "*", "Items", "*"}},
+			},
+		},
+		"keys": {
+			expression: `any Map as k, v { k == "a" and v == 1 and (all v as i, _ { i != 0 }) }`,
+			expected: []SelectorUsage{
+				{Selector: bexprSel("Map"), CollectionOperator: grammar.CollectionOpAny, Path: []string{"Map"}},
+				{Selector: bexprSel("k"), MatchOperator: grammar.MatchEqual, InCollection: true, LocalVariable: "k", Path: []string{"Map", "*"}, Key: true},
+				{Selector: bexprSel("v"), MatchOperator: grammar.MatchEqual, InCollection: true, LocalVariable: "v", Path: []string{"Map", "*"}},
+				{Selector: bexprSel("v"), CollectionOperator: grammar.CollectionOpAll, InCollection: true, LocalVariable: "v", Path: []string{"Map", "*"}},
+				{Selector: bexprSel("i"), MatchOperator: grammar.MatchNotEqual, InCollection: true, LocalVariable: "i", Path: []string{"Map", "*", "*"}, Key: true},
+			},
+		},
+		"default binding": {
+			expression: `any Map as k { k == "a" }`,
+			expected: []SelectorUsage{
+				{Selector: bexprSel("Map"), CollectionOperator: grammar.CollectionOpAny, Path: []string{"Map"}},
+				{Selector: bexprSel("k"), MatchOperator: grammar.MatchEqual, InCollection: true, LocalVariable: "k", Path: []string{"Map", "*"}},
+			},
+		},
+		"shadowing": {
+			expression: `any A as x { any x as x { x == 1 } }`,
+			expected: []SelectorUsage{
+				{Selector: bexprSel("A"), CollectionOperator: grammar.CollectionOpAny, Path: []string{"A"}},
+				{Selector: bexprSel("x"), CollectionOperator: grammar.CollectionOpAny, InCollection: true, LocalVariable: "x", Path: []string{"A", "*"}},
+				{Selector: bexprSel("x"), MatchOperator: grammar.MatchEqual, InCollection: true, LocalVariable: "x", Path: []string{"A", "*", "*"}},
+			},
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			eval, err := CreateEvaluator(tcase.expression)
+			require.NoError(t, err)
+			require.Equal(t, tcase.expected, eval.Selectors())
+		})
+	}
+}
+
+func TestSelectorUsage_Pointer(t *testing.T) {
+	t.Parallel()
+
+	u := SelectorUsage{Path: []string{"Map", "a/b", "*"}}
+	require.Equal(t, "/Map/a~1b/*", u.Pointer())
+}
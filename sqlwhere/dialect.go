@@ -0,0 +1,93 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlwhere
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// Postgres is the dialect of PostgreSQL, JSON values are read from jsonb
+	// columns.
+	Postgres Dialect = postgres{}
+
+	// SQLite is the dialect of SQLite, JSON values are read with the JSON1
+	// functions. It has no built-in regular expression support.
+	SQLite Dialect = sqlite{}
+)
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// arrayElementReplacer escapes the double quoted elements of PostgreSQL array
+// literals
+var arrayElementReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+type postgres struct{}
+
+func (postgres) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (postgres) QuoteIdentifier(name string) string {
+	return quoteIdentifier(name)
+}
+
+func (postgres) JSONPath(column string, path []string) (string, error) {
+	// The elements of the text[] literal are double quoted so the keys can
+	// contain commas, braces and spaces, with their double quotes and
+	// backslashes escaped
+	parts := make([]string, 0, len(path))
+	for _, p := range path {
+		parts = append(parts, `"`+arrayElementReplacer.Replace(p)+`"`)
+	}
+	return fmt.Sprintf("%s #>> %s", column, quoteString("{"+strings.Join(parts, ",")+"}")), nil
+}
+func (postgres) Contains(column, arg string) (string, error) {
+	return fmt.Sprintf("strpos(%s, %s) > 0", column, arg), nil
+}
+
+func (postgres) Matches(column, arg string) (string, error) {
+	return fmt.Sprintf("%s ~ %s", column, arg), nil
+}
+
+type sqlite struct{}
+
+func (sqlite) Placeholder(int) string {
+	return "?"
+}
+
+func (sqlite) QuoteIdentifier(name string) string {
+	return quoteIdentifier(name)
+}
+
+func (sqlite) JSONPath(column string, path []string) (string, error) {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, p := range path {
+		// The quoted labels of SQLite JSON paths end at the next double
+		// quote, which cannot be escaped
+		if strings.Contains(p, `"`) {
+			return "", fmt.Errorf("%w: JSON keys containing double quotes cannot be referenced in SQLite: %q", ErrUnsupported, p)
+		}
+		b.WriteString(`."`)
+		b.WriteString(p)
+		b.WriteString(`"`)
+	}
+	return fmt.Sprintf("json_extract(%s, %s)", column, quoteString(b.String())), nil
+}
+
+func (sqlite) Contains(column, arg string) (string, error) {
+	return fmt.Sprintf("instr(%s, %s) > 0", column, arg), nil
+}
+
+func (sqlite) Matches(string, string) (string, error) {
+	return "", fmt.Errorf("%w: regular expressions require a REGEXP extension in SQLite", ErrUnsupported)
+}
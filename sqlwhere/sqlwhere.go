@@ -0,0 +1,259 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package sqlwhere translates bexpr expressions into parameterized SQL WHERE
+// clauses, so the same filter can run in a database holding the data.
+//
+// Missing map keys are represented by NULL: operators that hold for a missing
+// key in bexpr, like != or "not in", also hold for NULL columns. Negations are
+// pushed down to the operators, `not (Name == "a")` being translated like
+// `Name != "a"`, so this also holds for negated expressions.
+package sqlwhere
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+)
+
+// ErrUnsupported is returned when an expression uses a construct the dialect
+// cannot express.
+var ErrUnsupported = errors.New("unsupported by SQL dialect")
+
+// Column describes where the value referenced by a selector is stored.
+type Column struct {
+	// Expression is the SQL expression reading the value, like a quoted
+	// column name or an access into a JSON column.
+	Expression string
+
+	// Convert converts the raw literals compared with the column into the
+	// arguments passed to the database, for example to an int64 for integer
+	// columns. Literals are passed as strings when it is nil.
+	Convert func(raw string) (interface{}, error)
+}
+
+// ColumnMapper returns the column referenced by a selector.
+type ColumnMapper func(sel grammar.Selector) (Column, error)
+
+// Dialect abstracts the differences between SQL databases.
+type Dialect interface {
+	// Placeholder returns the placeholder of the n-th argument, starting at 1.
+	Placeholder(n int) string
+
+	// QuoteIdentifier quotes a table or column name.
+	QuoteIdentifier(name string) string
+
+	// JSONPath returns the expression reading the text value at path in the
+	// JSON document stored in column. It fails for the keys the dialect
+	// cannot reference.
+	JSONPath(column string, path []string) (string, error)
+
+	// Contains returns the condition checking that the text expression
+	// column contains the substring arg.
+	Contains(column, arg string) (string, error)
+
+	// Matches returns the condition checking that the text expression column
+	// matches the regular expression arg.
+	Matches(column, arg string) (string, error)
+}
+
+// Config configures Translate.
+type Config struct {
+	Dialect Dialect
+	Columns ColumnMapper
+}
+
+// Translate converts expr into a SQL condition and the arguments for its
+// placeholders. Collection expressions are not supported.
+func Translate(expr grammar.Expression, config Config) (string, []interface{}, error) {
+	if config.Dialect == nil {
+		return "", nil, errors.New("no SQL dialect configured")
+	}
+	if config.Columns == nil {
+		return "", nil, errors.New("no column mapper configured")
+	}
+
+	t := translator{config: config}
+	where, err := t.translate(expr, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return where, t.args, nil
+}
+
+// MapColumns returns a ColumnMapper looking up the first segment of the
+// selectors in columns and reading the remaining segments, if any, from the
+// JSON document stored in that column.
+func MapColumns(dialect Dialect, columns map[string]string) ColumnMapper {
+	return func(sel grammar.Selector) (Column, error) {
+		if len(sel.Path) == 0 {
+			return Column{}, errors.New("empty selector")
+		}
+		name, ok := columns[sel.Path[0]]
+		if !ok {
+			return Column{}, fmt.Errorf("no column for selector %q", sel.String())
+		}
+		column := dialect.QuoteIdentifier(name)
+		if len(sel.Path) > 1 {
+			var err error
+			if column, err = dialect.JSONPath(column, sel.Path[1:]); err != nil {
+				return Column{}, err
+			}
+		}
+		return Column{Expression: column}, nil
+	}
+}
+
+type translator struct {
+	config Config
+	args   []interface{}
+}
+
+// negatedMatchOperators maps the match operators to their negation
+var negatedMatchOperators = map[grammar.MatchOperator]grammar.MatchOperator{
+	grammar.MatchEqual:      grammar.MatchNotEqual,
+	grammar.MatchNotEqual:   grammar.MatchEqual,
+	grammar.MatchIn:         grammar.MatchNotIn,
+	grammar.MatchNotIn:      grammar.MatchIn,
+	grammar.MatchIsEmpty:    grammar.MatchIsNotEmpty,
+	grammar.MatchIsNotEmpty: grammar.MatchIsEmpty,
+	grammar.MatchMatches:    grammar.MatchNotMatches,
+	grammar.MatchNotMatches: grammar.MatchMatches,
+	grammar.MatchIsNil:      grammar.MatchIsNotNil,
+	grammar.MatchIsNotNil:   grammar.MatchIsNil,
+	grammar.MatchIsOneOf:    grammar.MatchIsNotOneOf,
+	grammar.MatchIsNotOneOf: grammar.MatchIsOneOf,
+}
+
+// translate translates expr, or its negation when negate is set. Negations
+// are pushed down to the match expressions rather than translated to NOT,
+// since NOT of a condition on a NULL column is NULL and would exclude the
+// rows bexpr matches for missing keys.
+func (t *translator) translate(expr grammar.Expression, negate bool) (string, error) {
+	switch node := expr.(type) {
+	case *grammar.UnaryExpression:
+		if node.Operator != grammar.UnaryOpNot {
+			return "", fmt.Errorf("invalid unary operator: %s", node.Operator)
+		}
+		return t.translate(node.Operand, !negate)
+	case *grammar.BinaryExpression:
+		var op string
+		switch {
+		case node.Operator == grammar.BinaryOpAnd && !negate, node.Operator == grammar.BinaryOpOr && negate:
+			op = "AND"
+		case node.Operator == grammar.BinaryOpOr, node.Operator == grammar.BinaryOpAnd:
+			op = "OR"
+		default:
+			return "", fmt.Errorf("invalid binary operator: %s", node.Operator)
+		}
+		left, err := t.translate(node.Left, negate)
+		if err != nil {
+			return "", err
+		}
+		right, err := t.translate(node.Right, negate)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s) %s (%s)", left, op, right), nil
+	case *grammar.MatchExpression:
+		operator := node.Operator
+		if negate {
+			var ok bool
+			if operator, ok = negatedMatchOperators[operator]; !ok {
+				return "", fmt.Errorf("invalid match operation: %d", node.Operator)
+			}
+		}
+		return t.translateMatch(node, operator)
+	case *grammar.ConstantExpression:
+		if node.Value != negate {
+			return "1 = 1", nil
+		}
+		return "1 = 0", nil
+	case *grammar.CollectionExpression:
+		return "", fmt.Errorf("%w: %s expression on %s", ErrUnsupported, strings.ToLower(string(node.Op)), node.Selector)
+	default:
+		return "", fmt.Errorf("invalid AST node: %T", expr)
+	}
+}
+
+// arg registers an argument and returns its placeholder
+func (t *translator) arg(column Column, value *grammar.MatchValue) (string, error) {
+	var arg interface{} = value.Raw
+	if column.Convert != nil {
+		var err error
+		if arg, err = column.Convert(value.Raw); err != nil {
+			return "", fmt.Errorf("error converting %q: %w", value.Raw, err)
+		}
+	}
+	t.args = append(t.args, arg)
+	return t.config.Dialect.Placeholder(len(t.args)), nil
+}
+
+// translateMatch translates expr with its operator replaced by operator
+func (t *translator) translateMatch(expr *grammar.MatchExpression, operator grammar.MatchOperator) (string, error) {
+	column, err := t.config.Columns(expr.Selector)
+	if err != nil {
+		return "", err
+	}
+	col := column.Expression
+
+	switch operator {
+	case grammar.MatchEqual, grammar.MatchNotEqual:
+		ph, err := t.arg(column, expr.Value)
+		if err != nil {
+			return "", err
+		}
+		if operator == grammar.MatchEqual {
+			return fmt.Sprintf("%s = %s", col, ph), nil
+		}
+		return fmt.Sprintf("(%s IS NULL OR %s <> %s)", col, col, ph), nil
+
+	case grammar.MatchIsOneOf, grammar.MatchIsNotOneOf:
+		placeholders := make([]string, 0, len(expr.Values))
+		for _, v := range expr.Values {
+			ph, err := t.arg(column, v)
+			if err != nil {
+				return "", err
+			}
+			placeholders = append(placeholders, ph)
+		}
+		list := strings.Join(placeholders, ", ")
+		if operator == grammar.MatchIsOneOf {
+			return fmt.Sprintf("%s IN (%s)", col, list), nil
+		}
+		return fmt.Sprintf("(%s IS NULL OR %s NOT IN (%s))", col, col, list), nil
+
+	case grammar.MatchIn, grammar.MatchNotIn, grammar.MatchMatches, grammar.MatchNotMatches:
+		// The literal is a substring or a pattern, never converted to the
+		// type of the column
+		t.args = append(t.args, expr.Value.Raw)
+		ph := t.config.Dialect.Placeholder(len(t.args))
+
+		var cond string
+		if operator == grammar.MatchIn || operator == grammar.MatchNotIn {
+			cond, err = t.config.Dialect.Contains(col, ph)
+		} else {
+			cond, err = t.config.Dialect.Matches(col, ph)
+		}
+		if err != nil {
+			return "", err
+		}
+		if operator == grammar.MatchIn || operator == grammar.MatchMatches {
+			return cond, nil
+		}
+		return fmt.Sprintf("(%s IS NULL OR NOT (%s))", col, cond), nil
+
+	case grammar.MatchIsEmpty:
+		return fmt.Sprintf("(%s IS NULL OR %s = '')", col, col), nil
+	case grammar.MatchIsNotEmpty:
+		return fmt.Sprintf("(%s IS NOT NULL AND %s <> '')", col, col), nil
+	case grammar.MatchIsNil:
+		return fmt.Sprintf("%s IS NULL", col), nil
+	case grammar.MatchIsNotNil:
+		return fmt.Sprintf("%s IS NOT NULL", col), nil
+	default:
+		return "", fmt.Errorf("invalid match operation: %d", operator)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package sqlwhere
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/go-bexpr/grammar"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, input string) grammar.Expression {
+	t.Helper()
+	expr, err := grammar.Parse("", []byte(input))
+	require.NoError(t, err)
+	return expr.(grammar.Expression)
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	columns := map[string]string{
+		"Name":   "name",
+		"Port":   "port",
+		"Labels": "labels",
+		"Tags":   "tags",
+	}
+
+	type testCase struct {
+		input    string
+		dialect  Dialect
+		where    string
+		args     []interface{}
+		err      string
+		simplify bool
+		// ast is translated instead of input when set
+		ast grammar.Expression
+	}
+
+	tests := map[string]testCase{
+		"equal": {
+			input:   `Name == "web"`,
+			dialect: Postgres,
+			where:   `"name" = $1`,
+			args:    []interface{}{"web"},
+		},
+		"not equal": {
+			input:   `Name != "web"`,
+			dialect: SQLite,
+			where:   `("name" IS NULL OR "name" <> ?)`,
+			args:    []interface{}{"web"},
+		},
+		"converted argument": {
+			input:   `Port == 8080`,
+			dialect: Postgres,
+			where:   `"port" = $1`,
+			args:    []interface{}{int64(8080)},
+		},
+		"json path": {
+			input:   `Labels.env == "prod" and Labels.tier != "frontend"`,
+			dialect: Postgres,
+			where:   `("labels" #>> '{"env"}' = $1) AND (("labels" #>> '{"tier"}' IS NULL OR "labels" #>> '{"tier"}' <> $2))`,
+			args:    []interface{}{"prod", "frontend"},
+		},
+		"json path sqlite": {
+			input:   `"/Labels/app.kubernetes.io~1name" == "web"`,
+			dialect: SQLite,
+			where:   `json_extract("labels", '$."app.kubernetes.io/name"') = ?`,
+			args:    []interface{}{"web"},
+		},
+		"or and not": {
+			input:   `not (Name == "a" or Name == "b")`,
+			dialect: Postgres,
+			where:   `(("name" IS NULL OR "name" <> $1)) AND (("name" IS NULL OR "name" <> $2))`,
+			args:    []interface{}{"a", "b"},
+		},
+		"not equal to": {
+			input:   `not (Name == "a")`,
+			dialect: SQLite,
+			where:   `("name" IS NULL OR "name" <> ?)`,
+			args:    []interface{}{"a"},
+		},
+		"not not equal to": {
+			input:   `not (Name != "a")`,
+			dialect: SQLite,
+			where:   `"name" = ?`,
+			args:    []interface{}{"a"},
+		},
+		"not in": {
+			input:   `not ("web" in Tags)`,
+			dialect: SQLite,
+			where:   `("tags" IS NULL OR NOT (instr("tags", ?) > 0))`,
+			args:    []interface{}{"web"},
+		},
+		"not is one of": {
+			ast: &grammar.UnaryExpression{Operator: grammar.UnaryOpNot, Operand: &grammar.MatchExpression{
+				Selector: grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: []string{"Name"}},
+				Operator: grammar.MatchIsOneOf,
+				Values:   []*grammar.MatchValue{{Raw: "a"}, {Raw: "b"}},
+			}},
+			dialect: Postgres,
+			where:   `("name" IS NULL OR "name" NOT IN ($1, $2))`,
+			args:    []interface{}{"a", "b"},
+		},
+		"not and": {
+			input:   `not (Name == "a" and "web" in Tags)`,
+			dialect: SQLite,
+			where:   `(("name" IS NULL OR "name" <> ?)) OR (("tags" IS NULL OR NOT (instr("tags", ?) > 0)))`,
+			args:    []interface{}{"a", "web"},
+		},
+		"double negation": {
+			input:   `not (not (Name == "a" or Labels is nil) and Tags is not empty)`,
+			dialect: SQLite,
+			where:   `(("name" = ?) OR ("labels" IS NULL)) OR (("tags" IS NULL OR "tags" = ''))`,
+			args:    []interface{}{"a"},
+		},
+		"not constant": {
+			ast: &grammar.UnaryExpression{Operator: grammar.UnaryOpNot, Operand: &grammar.BinaryExpression{
+				Operator: grammar.BinaryOpAnd,
+				Left: &grammar.MatchExpression{
+					Selector: grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: []string{"Name"}},
+					Operator: grammar.MatchEqual,
+					Value:    &grammar.MatchValue{Raw: "a"},
+				},
+				Right: &grammar.ConstantExpression{Value: false},
+			}},
+			dialect: SQLite,
+			where:   `(("name" IS NULL OR "name" <> ?)) OR (1 = 1)`,
+			args:    []interface{}{"a"},
+		},
+		"one of": {
+			input:    `Name == "a" or Name == "b" or Name == "c"`,
+			dialect:  Postgres,
+			simplify: true,
+			where:    `"name" IN ($1, $2, $3)`,
+			args:     []interface{}{"a", "b", "c"},
+		},
+		"not one of": {
+			input:    `Name != "a" and Name != "b"`,
+			dialect:  SQLite,
+			simplify: true,
+			where:    `("name" IS NULL OR "name" NOT IN (?, ?))`,
+			args:     []interface{}{"a", "b"},
+		},
+		"contains": {
+			input:   `Tags contains "web" and "db" not in Tags`,
+			dialect: Postgres,
+			where:   `(strpos("tags", $1) > 0) AND (("tags" IS NULL OR NOT (strpos("tags", $2) > 0)))`,
+			args:    []interface{}{"web", "db"},
+		},
+		"contains sqlite": {
+			input:   `Tags contains "web"`,
+			dialect: SQLite,
+			where:   `instr("tags", ?) > 0`,
+			args:    []interface{}{"web"},
+		},
+		"matches": {
+			input:   `Name matches "^web-[0-9]+$"`,
+			dialect: Postgres,
+			where:   `"name" ~ $1`,
+			args:    []interface{}{"^web-[0-9]+$"},
+		},
+		"matches sqlite": {
+			input:   `Name matches "^web"`,
+			dialect: SQLite,
+			err:     "unsupported by SQL dialect: regular expressions require a REGEXP extension in SQLite",
+		},
+		"empty and nil": {
+			input:   `Name is empty or Tags is not empty or Labels is nil or Port is not nil`,
+			dialect: SQLite,
+			where:   `(("name" IS NULL OR "name" = '')) OR ((("tags" IS NOT NULL AND "tags" <> '')) OR (("labels" IS NULL) OR ("port" IS NOT NULL)))`,
+		},
+		"collection": {
+			input:   `any Tags as t { t == "web" }`,
+			dialect: Postgres,
+			err:     "unsupported by SQL dialect: any expression on Tags",
+		},
+		"unknown column": {
+			input:   `Secret == "x"`,
+			dialect: Postgres,
+			err:     `no column for selector "Secret"`,
+		},
+		"conversion error": {
+			input:   `Port == "http"`,
+			dialect: Postgres,
+			err:     `error converting "http"`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			mapper := MapColumns(tcase.dialect, columns)
+			config := Config{
+				Dialect: tcase.dialect,
+				Columns: func(sel grammar.Selector) (Column, error) {
+					column, err := mapper(sel)
+					if err == nil && sel.Path[0] == "Port" {
+						column.Convert = func(raw string) (interface{}, error) {
+							return strconv.ParseInt(raw, 10, 64)
+						}
+					}
+					return column, err
+				},
+			}
+
+			expr := tcase.ast
+			if expr == nil {
+				expr = parse(t, tcase.input)
+			}
+			if tcase.simplify {
+				expr = grammar.Simplify(expr)
+			}
+			where, args, err := Translate(expr, config)
+			if tcase.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tcase.where, where)
+			require.Equal(t, tcase.args, args)
+		})
+	}
+}
+
+func TestTranslate_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := Translate(parse(t, `Name matches "x"`), Config{
+		Dialect: SQLite,
+		Columns: MapColumns(SQLite, map[string]string{"Name": "name"}),
+	})
+	require.True(t, errors.Is(err, ErrUnsupported))
+
+	// SQLite cannot escape the double quotes of JSON keys
+	_, _, err = Translate(&grammar.MatchExpression{
+		Selector: grammar.Selector{Type: grammar.SelectorTypeBexpr, Path: []string{"Labels", `a"b`}},
+		Operator: grammar.MatchEqual,
+		Value:    &grammar.MatchValue{Raw: "x"},
+	}, Config{
+		Dialect: SQLite,
+		Columns: MapColumns(SQLite, map[string]string{"Labels": "labels"}),
+	})
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		dialect Dialect
+		path    []string
+		expr    string
+		err     string
+	}
+
+	tests := map[string]testCase{
+		"postgres": {
+			dialect: Postgres,
+			path:    []string{"env", "0"},
+			expr:    `"labels" #>> '{"env","0"}'`,
+		},
+		"postgres special characters": {
+			dialect: Postgres,
+			path:    []string{"a,b", "{c} d", ""},
+			expr:    `"labels" #>> '{"a,b","{c} d",""}'`,
+		},
+		"postgres quotes and backslashes": {
+			dialect: Postgres,
+			path:    []string{`say "hi"`, `C:\dir`, "it's"},
+			expr:    `"labels" #>> '{"say \"hi\"","C:\\dir","it''s"}'`,
+		},
+		"sqlite": {
+			dialect: SQLite,
+			path:    []string{"app.kubernetes.io/name", "it's", `C:\dir`},
+			expr:    `json_extract("labels", '$."app.kubernetes.io/name"."it''s"."C:\dir"')`,
+		},
+		"sqlite double quotes": {
+			dialect: SQLite,
+			path:    []string{"env", `say "hi"`},
+			err:     `unsupported by SQL dialect: JSON keys containing double quotes cannot be referenced in SQLite: "say \"hi\""`,
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := tcase.dialect.JSONPath(`"labels"`, tcase.path)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tcase.expr, expr)
+		})
+	}
+}
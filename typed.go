@@ -0,0 +1,158 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/pointerstructure"
+)
+
+// TypedEvaluator evaluates an expression against values of a type known when
+// it is created, see CompileFor. Like Evaluator, it is safe for concurrent
+// use.
+type TypedEvaluator[T any] struct {
+	eval    *Evaluator
+	program compiled
+	// iface is true when T is an interface type, whose values are evaluated
+	// as is
+	iface bool
+}
+
+// CompileFor creates an evaluator for the values of type T. The selectors
+// that only go through struct fields, and pointers to structs, are resolved
+// to field indexes once, so evaluating them does not need to look fields up by
+// name, and selectors that cannot be found in T are reported here rather than
+// on every evaluation. Selectors going through interfaces, maps, slices or
+// the local variables of collection expressions are resolved during
+// evaluation, like with Evaluate.
+//
+// It supports the same options as CreateEvaluator. When WithHookFn is used,
+// every selector is resolved during evaluation since the hook can change the
+// values met along the way.
+func CompileFor[T any](expression string, opts ...Option) (*TypedEvaluator[T], error) {
+	eval, err := CreateEvaluator(expression, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	c := compiler{budgeted: eval.budgeted}
+	if eval.valueTransformationHook == nil {
+		tagName := eval.tagName
+		if tagName == "" {
+			// The default of pointerstructure
+			tagName = "pointer"
+		}
+		c.static = func(path []string) ([]int, bool, error) {
+			for _, lv := range eval.evalOpts.withLocalVariables {
+				if len(path) > 0 && path[0] == lv.name {
+					// Local variables are resolved during evaluation
+					return nil, false, nil
+				}
+			}
+			fields, ok, err := staticFields(typ, path, tagName)
+			if err != nil {
+				if eval.unknownVal != nil && errors.Is(err, pointerstructure.ErrNotFound) {
+					// Let the evaluation use the unknown value
+					return nil, false, nil
+				}
+				ptr := pointerstructure.Pointer{Parts: path}
+				return nil, false, fmt.Errorf("invalid selector %s for %s: %w", ptr.String(), typ, err)
+			}
+			return fields, ok, nil
+		}
+	}
+
+	root, err := c.compile(eval.ast)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedEvaluator[T]{
+		eval:    eval,
+		program: root.program,
+		iface:   typ.Kind() == reflect.Interface,
+	}, nil
+}
+
+// Evaluate attempts to match the expression against value, like
+// Evaluator.Evaluate.
+func (e *TypedEvaluator[T]) Evaluate(value T) (bool, error) {
+	if e.iface {
+		return e.program(value, e.eval.runOptions(nil))
+	}
+	// Static selectors are resolved from a pointer to the value, which
+	// pointerstructure handles like the value itself for the other ones
+	return e.program(&value, e.eval.runOptions(nil))
+}
+
+// Evaluator returns the untyped evaluator of the expression.
+func (e *TypedEvaluator[T]) Evaluator() *Evaluator {
+	return e.eval
+}
+
+// staticFields resolves path in typ to the indexes of the struct fields to
+// follow, the same way pointerstructure looks struct fields up. ok is false
+// when a value whose type is not a struct is met before the end of the path.
+func staticFields(typ reflect.Type, path []string, tagName string) ([]int, bool, error) {
+	fields := make([]int, 0, len(path))
+	for _, part := range path {
+		typ = derefType(typ)
+		if typ.Kind() != reflect.Struct {
+			return nil, false, nil
+		}
+		field, err := structField(typ, part, tagName)
+		if err != nil {
+			return nil, false, err
+		}
+		fields = append(fields, field)
+		typ = typ.Field(field).Type
+	}
+	return fields, true, nil
+}
+
+// structField mirrors the struct field lookup of pointerstructure
+func structField(typ reflect.Type, part string, tagName string) (int, error) {
+	found, ignored := -1, false
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fieldTag := field.Tag.Get(tagName)
+		if fieldTag == "" {
+			if field.Name == part {
+				found = i
+			}
+			continue
+		}
+		if idx := strings.Index(fieldTag, ","); idx != -1 {
+			fieldTag = fieldTag[0:idx]
+		}
+		if strings.Contains(fieldTag, "|") {
+			return 0, fmt.Errorf("pointer struct tag cannot contain the '|' character")
+		}
+		if fieldTag == "-" {
+			if field.Name == part {
+				found, ignored = i, true
+			}
+			continue
+		}
+		if fieldTag == part {
+			return i, nil
+		}
+	}
+
+	if found < 0 {
+		return 0, fmt.Errorf("%w: struct field with name %q", pointerstructure.ErrNotFound, part)
+	}
+	if ignored {
+		return 0, fmt.Errorf("struct field %q is ignored and cannot be used", part)
+	}
+	return found, nil
+}
@@ -0,0 +1,233 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package bexpr
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func checkTyped[T any](t *testing.T, expTest expressionCheck, value T) {
+	t.Helper()
+
+	eval, err := CompileFor[T](expTest.expression, WithHookFn(expTest.hook))
+	if err != nil {
+		// Invalid selectors are reported early instead of on evaluation
+		require.NotEmpty(t, expTest.err, "unexpected error: %v", err)
+		return
+	}
+
+	match, err := eval.Evaluate(value)
+	if expTest.err != "" {
+		require.EqualError(t, err, expTest.err)
+	} else {
+		require.NoError(t, err)
+	}
+	require.Equal(t, expTest.result, match)
+}
+
+func TestCompileFor(t *testing.T) {
+	t.Parallel()
+	for name, tcase := range evaluateTests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for i, expTest := range tcase.expressions {
+				expTest := expTest
+				t.Run(fmt.Sprintf("#%d - %s", i, expTest.expression), func(t *testing.T) {
+					t.Parallel()
+
+					switch value := tcase.value.(type) {
+					case testFlatStruct:
+						checkTyped(t, expTest, value)
+						checkTyped(t, expTest, &value)
+					case testFlatStructAlt:
+						checkTyped(t, expTest, value)
+					case testNestedTypes:
+						checkTyped(t, expTest, value)
+						checkTyped(t, expTest, &value)
+					case map[string]map[string]bool:
+						checkTyped(t, expTest, value)
+					default:
+						t.Fatalf("unexpected type %T", value)
+					}
+					checkTyped[interface{}](t, expTest, tcase.value)
+				})
+			}
+		})
+	}
+}
+
+type testTypedStruct struct {
+	Name    string `bexpr:"name"`
+	Port    int
+	Number  json.Number
+	Any     interface{}
+	Nested  *testTypedStruct
+	Labels  map[string]string
+	Ignored string `bexpr:"-"`
+}
+
+func TestCompileFor_Validation(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		expression string
+		opts       []Option
+		err        string
+	}
+
+	tests := map[string]testCase{
+		"valid": {
+			expression: `name == web and Nested.Nested.Port == 80 and Labels.env == prod and Any.foo == bar`,
+		},
+		"unknown field": {
+			expression: `Nested.Missing == 1`,
+			err:        `invalid selector /Nested/Missing for bexpr.testTypedStruct: couldn't find key: struct field with name "Missing"`,
+		},
+		"renamed field": {
+			expression: `Name == web`,
+			err:        `invalid selector /Name for bexpr.testTypedStruct: couldn't find key: struct field with name "Name"`,
+		},
+		"ignored field": {
+			expression: `Ignored == x`,
+			err:        `invalid selector /Ignored for bexpr.testTypedStruct: struct field "Ignored" is ignored and cannot be used`,
+		},
+		"collection": {
+			expression: `any Nope as n { n == 1 }`,
+			err:        `invalid selector /Nope for bexpr.testTypedStruct: couldn't find key: struct field with name "Nope"`,
+		},
+		"unknown value": {
+			expression: `Missing == 1`,
+			opts:       []Option{WithUnknownValue("")},
+		},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := CompileFor[testTypedStruct](tcase.expression, tcase.opts...)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCompileFor_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	value := testTypedStruct{
+		Name:   "web",
+		Port:   80,
+		Number: json.Number("42"),
+		Any:    map[string]string{"foo": "bar"},
+		Labels: map[string]string{"env": "prod"},
+	}
+
+	type testCase struct {
+		expression string
+		result     bool
+		err        string
+	}
+
+	tests := map[string]testCase{
+		"field":          {expression: `name == web and Port == 80`, result: true},
+		"json number":    {expression: `Number == 42`, result: true},
+		"interface":      {expression: `Any.foo == bar`, result: true},
+		"map":            {expression: `Labels.env == prod and Labels.tier != web`, result: true},
+		"nil pointer":    {expression: `Nested is nil`, result: true},
+		"through nil":    {expression: `Nested.Port == 80`, err: "error finding value in datum: /Nested/Port: at part 1, invalid value kind: invalid"},
+		"collection":     {expression: `any Labels as k, v { k == env and v == prod }`, result: true},
+		"unknown values": {expression: `Any is not nil`, result: true},
+	}
+
+	for name, tcase := range tests {
+		tcase := tcase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			typed, err := CompileFor[testTypedStruct](tcase.expression)
+			require.NoError(t, err)
+			match, err := typed.Evaluate(value)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tcase.result, match)
+
+			// The untyped evaluator gives the same result
+			expected, expectedErr := typed.Evaluator().Evaluate(value)
+			require.Equal(t, expected, match)
+			if expectedErr != nil {
+				require.EqualError(t, err, expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestCompileFor_Allocations(t *testing.T) {
+	value := testTypedStruct{Name: "web", Port: 80, Nested: &testTypedStruct{Port: 8080}}
+
+	typed, err := CompileFor[*testTypedStruct](`name == web and Port == 80 and Nested.Port != 80`)
+	require.NoError(t, err)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		match, err := typed.Evaluate(&value)
+		if err != nil || !match {
+			t.Fatal("unexpected result", match, err)
+		}
+	})
+	// Only the pointer to the evaluated value escapes
+	require.LessOrEqual(t, allocs, 1.0)
+}
+
+func BenchmarkCompileFor(b *testing.B) {
+	value := testTypedStruct{Name: "web", Port: 80, Nested: &testTypedStruct{Port: 8080}}
+	expression := `name == web and Port == 80 and Nested.Port != 80`
+
+	b.Run("Evaluator", func(b *testing.B) {
+		eval, err := CreateEvaluator(expression)
+		require.NoError(b, err)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			_, err = eval.Evaluate(value)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("TypedEvaluator", func(b *testing.B) {
+		eval, err := CompileFor[testTypedStruct](expression)
+		require.NoError(b, err)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			_, err = eval.Evaluate(value)
+			require.NoError(b, err)
+		}
+	})
+}
+
+func TestCompileFor_LocalVariables(t *testing.T) {
+	t.Parallel()
+
+	// Local variables are not fields of the type
+	eval, err := CompileFor[testTypedStruct](`name == "web" and env == "prod"`,
+		WithLocalVariable("env", []string{"Labels", "env"}, nil))
+	require.NoError(t, err)
+
+	match, err := eval.Evaluate(testTypedStruct{Name: "web", Labels: map[string]string{"env": "prod"}})
+	require.NoError(t, err)
+	require.True(t, match)
+}